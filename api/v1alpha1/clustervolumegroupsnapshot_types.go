@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterVolumeGroupSnapshotSpec defines the desired state of ClusterVolumeGroupSnapshot
+type ClusterVolumeGroupSnapshotSpec struct {
+	// ClusterVolumeGroupName is the name of the ClusterVolumeGroup to take snapshots of
+	ClusterVolumeGroupName string `json:"clusterVolumeGroupName"`
+
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass used to take
+	// snapshots of all the volumes in the group.
+	// If not specified, the default VolumeSnapshotClass for the CSI driver of
+	// each volume is used.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Timeout is the duration from the creation of the ClusterVolumeGroupSnapshot within which
+	// all the snapshots need to become ready to use. Otherwise, the group snapshot fails.
+	// If not specified, the default timeout of the controller is used.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ClusterVolumeGroupSnapshotStatus defines the observed state of ClusterVolumeGroupSnapshot
+type ClusterVolumeGroupSnapshotStatus struct {
+	// ReadyToUse becomes true when ReadyToUse on all individual snapshots in all namespaces become true
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty"`
+
+	// +optional
+	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
+
+	// Members lists the snapshots taken for the persistent volume claims in the volume group.
+	// Members are fixed when the group snapshot is first reconciled.
+	// +optional
+	Members []ClusterVolumeGroupSnapshotMember `json:"members,omitempty"`
+
+	// Namespaces summarizes the members per namespace
+	// +optional
+	Namespaces []ClusterVolumeGroupSnapshotNamespace `json:"namespaces,omitempty"`
+
+	// ObservedGeneration is the generation observed when the status was last updated
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the group snapshot's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ClusterVolumeGroupSnapshotMember describes a snapshot of a persistent volume claim in the group snapshot
+type ClusterVolumeGroupSnapshotMember struct {
+	// Namespace is the namespace of the persistent volume claim and the volume snapshot
+	Namespace string `json:"namespace"`
+
+	// PersistentVolumeClaimName is the name of the persistent volume claim
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+
+	// VolumeSnapshotName is the name of the volume snapshot for the persistent volume claim
+	VolumeSnapshotName string `json:"volumeSnapshotName"`
+
+	// ReadyToUse is ReadyToUse of the volume snapshot
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty"`
+
+	// Error is the error reported by the volume snapshot
+	// +optional
+	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
+}
+
+// ClusterVolumeGroupSnapshotNamespace summarizes the members of the group snapshot in a namespace
+type ClusterVolumeGroupSnapshotNamespace struct {
+	// Namespace is the name of the namespace
+	Namespace string `json:"namespace"`
+
+	// MemberCount is the number of volume snapshots in the namespace
+	MemberCount int32 `json:"memberCount"`
+
+	// ReadyCount is the number of volume snapshots in the namespace that are ready to use
+	ReadyCount int32 `json:"readyCount"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=cvgs
+//+kubebuilder:printcolumn:name="ReadyToUse",type=boolean,JSONPath=`.status.readyToUse`,description="Indicates if all the snapshots in the clusterVolumeGroupSnapshot are ready to be used to restore volumes."
+//+kubebuilder:printcolumn:name="ClusterVolumeGroup",type=string,JSONPath=`.spec.clusterVolumeGroupName`,description="Name of the ClusterVolumeGroup from which this clusterVolumeGroupSnapshot was (or will be) created."
+
+// ClusterVolumeGroupSnapshot is the Schema for the clustervolumegroupsnapshots API
+type ClusterVolumeGroupSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterVolumeGroupSnapshotSpec   `json:"spec,omitempty"`
+	Status ClusterVolumeGroupSnapshotStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterVolumeGroupSnapshotList contains a list of ClusterVolumeGroupSnapshot
+type ClusterVolumeGroupSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterVolumeGroupSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterVolumeGroupSnapshot{}, &ClusterVolumeGroupSnapshotList{})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterVolumeGroupSnapshotContentSpec defines the desired state of ClusterVolumeGroupSnapshotContent
+type ClusterVolumeGroupSnapshotContentSpec struct {
+	// VolumeGroupSnapshotRef specifies the VolumeGroupSnapshot object to which this
+	// ClusterVolumeGroupSnapshotContent object is bound.
+	// Namespace and Name are required. If UID is not specified, it is set when the
+	// VolumeGroupSnapshot binds to this ClusterVolumeGroupSnapshotContent.
+	VolumeGroupSnapshotRef corev1.ObjectReference `json:"volumeGroupSnapshotRef"`
+
+	// VolumeSnapshotContentNames is the list of the names of the pre-provisioned
+	// VolumeSnapshotContents in the group.
+	// Each VolumeSnapshotContent needs the Retain deletionPolicy and a volumeSnapshotRef to
+	// the VolumeSnapshot "vs-<ClusterVolumeGroupSnapshotContent name>-<VolumeSnapshotContent name>"
+	// in the namespace of the VolumeGroupSnapshot, which is created by the controller.
+	// Names longer than 253 characters are shortened with a hash.
+	// +kubebuilder:validation:MinItems=1
+	VolumeSnapshotContentNames []string `json:"volumeSnapshotContentNames"`
+
+	// DeletionPolicy decides whether the VolumeSnapshotContents are deleted
+	// when the ClusterVolumeGroupSnapshotContent is deleted.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// ClusterVolumeGroupSnapshotContentStatus defines the observed state of ClusterVolumeGroupSnapshotContent
+type ClusterVolumeGroupSnapshotContentStatus struct {
+	// ReadyToUse becomes true when ReadyToUse of the VolumeGroupSnapshotContent in the namespace becomes true
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty"`
+
+	// Error is copied from the VolumeGroupSnapshotContent in the namespace when it fails
+	// +optional
+	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
+
+	// VolumeGroupSnapshotContentName is the name of the VolumeGroupSnapshotContent created
+	// in the namespace of the VolumeGroupSnapshot
+	// +optional
+	VolumeGroupSnapshotContentName *string `json:"volumeGroupSnapshotContentName,omitempty"`
+
+	// ObservedGeneration is the generation observed when the status was last updated
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the group snapshot content's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=cvgsc
+//+kubebuilder:printcolumn:name="ReadyToUse",type=boolean,JSONPath=`.status.readyToUse`,description="Indicates if the clusterVolumeGroupSnapshotContent is ready to be used to restore volumes."
+//+kubebuilder:printcolumn:name="DeletionPolicy",type=string,JSONPath=`.spec.deletionPolicy`,description="Determines whether the VolumeSnapshotContents of this clusterVolumeGroupSnapshotContent should be deleted when the clusterVolumeGroupSnapshotContent is deleted."
+//+kubebuilder:printcolumn:name="VolumeGroupSnapshotNamespace",type=string,JSONPath=`.spec.volumeGroupSnapshotRef.namespace`,description="Namespace of the VolumeGroupSnapshot object to which this clusterVolumeGroupSnapshotContent object is bound."
+//+kubebuilder:printcolumn:name="VolumeGroupSnapshot",type=string,JSONPath=`.spec.volumeGroupSnapshotRef.name`,description="Name of the VolumeGroupSnapshot object to which this clusterVolumeGroupSnapshotContent object is bound."
+
+// ClusterVolumeGroupSnapshotContent is the Schema for the clustervolumegroupsnapshotcontents API
+type ClusterVolumeGroupSnapshotContent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterVolumeGroupSnapshotContentSpec   `json:"spec,omitempty"`
+	Status ClusterVolumeGroupSnapshotContentStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterVolumeGroupSnapshotContentList contains a list of ClusterVolumeGroupSnapshotContent
+type ClusterVolumeGroupSnapshotContentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterVolumeGroupSnapshotContent `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterVolumeGroupSnapshotContent{}, &ClusterVolumeGroupSnapshotContentList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Condition types of VolumeGroup, VolumeGroupSnapshot, VolumeGroupSnapshotContent, ClusterVolumeGroupSnapshot
+// and ClusterVolumeGroupSnapshotContent
+const (
+	// ConditionMembersResolved indicates whether the PersistentVolumeClaims in the group are resolved
+	ConditionMembersResolved = "MembersResolved"
+
+	// ConditionSnapshotBound indicates whether the ClusterVolumeGroupSnapshotContent is bound to its VolumeGroupSnapshot
+	ConditionSnapshotBound = "SnapshotBound"
+
+	// ConditionContentBound indicates whether the VolumeGroupSnapshot is bound to its VolumeGroupSnapshotContent
+	ConditionContentBound = "ContentBound"
+
+	// ConditionSnapshotsCreated indicates whether VolumeSnapshots are created for all the members
+	ConditionSnapshotsCreated = "SnapshotsCreated"
+
+	// ConditionReady indicates whether all the VolumeSnapshots are ready to use
+	ConditionReady = "Ready"
+
+	// ConditionFailed indicates whether the group snapshot has failed
+	ConditionFailed = "Failed"
+
+	// ConditionPartial indicates whether only a part of the member VolumeSnapshots is left after the failure
+	ConditionPartial = "Partial"
+
+	// ConditionCrashConsistent indicates whether CreationTimeSkew is within MaxSkew
+	ConditionCrashConsistent = "CrashConsistent"
+
+	// ConditionPreSnapshotHookSucceeded indicates whether the pre-snapshot hook Job has succeeded
+	ConditionPreSnapshotHookSucceeded = "PreSnapshotHookSucceeded"
+
+	// ConditionPostSnapshotHookSucceeded indicates whether the post-snapshot hook Job has succeeded
+	ConditionPostSnapshotHookSucceeded = "PostSnapshotHookSucceeded"
+
+	// ConditionPreSnapshotHTTPHookSucceeded indicates whether the pre-snapshot HTTP hook has succeeded
+	ConditionPreSnapshotHTTPHookSucceeded = "PreSnapshotHTTPHookSucceeded"
+
+	// ConditionPostSnapshotHTTPHookSucceeded indicates whether the post-snapshot HTTP hook has succeeded
+	ConditionPostSnapshotHTTPHookSucceeded = "PostSnapshotHTTPHookSucceeded"
+
+	// ConditionQuiesced indicates whether the workloads are scaled down for the group snapshot
+	ConditionQuiesced = "Quiesced"
+
+	// ConditionDeleting indicates the progress of the deletion of the group snapshot
+	ConditionDeleting = "Deleting"
+)
+
+// Reasons of the conditions of VolumeGroup, VolumeGroupSnapshot, VolumeGroupSnapshotContent, ClusterVolumeGroupSnapshot
+// and ClusterVolumeGroupSnapshotContent
+const (
+	ReasonBound               = "Bound"
+	ReasonWaitingForContent   = "WaitingForContent"
+	ReasonContentNotFound     = "ContentNotFound"
+	ReasonBindingMismatch     = "BindingMismatch"
+	ReasonSnapshotNotFound    = "SnapshotNotFound"
+	ReasonCreating            = "Creating"
+	ReasonCreated             = "Created"
+	ReasonWaitingForSnapshots = "WaitingForSnapshots"
+	ReasonReady               = "Ready"
+	ReasonNoFailure           = "NoFailure"
+	ReasonSnapshotFailed      = "SnapshotFailed"
+	ReasonSnapshotConflict    = "SnapshotConflict"
+	ReasonContentConflict     = "ContentConflict"
+	ReasonTimeout             = "Timeout"
+	ReasonRolledBack          = "RolledBack"
+	ReasonWithinMaxSkew       = "WithinMaxSkew"
+	ReasonMaxSkewExceeded     = "MaxSkewExceeded"
+	ReasonRetained            = "Retained"
+	ReasonNotRetained         = "NotRetained"
+	ReasonDeletingSnapshots   = "DeletingSnapshots"
+	ReasonDeletingContent     = "DeletingContent"
+	ReasonHookRunning         = "HookRunning"
+	ReasonHookRetrying        = "HookRetrying"
+	ReasonHookSucceeded       = "HookSucceeded"
+	ReasonHookFailed          = "HookFailed"
+	ReasonHookTimeout         = "HookTimeout"
+	ReasonHookSkipped         = "HookSkipped"
+	ReasonScalingDown         = "ScalingDown"
+	ReasonScaledDown          = "ScaledDown"
+	ReasonRestored            = "Restored"
+	ReasonWorkloadNotFound    = "WorkloadNotFound"
+	ReasonWorkloadNotMember   = "WorkloadNotMember"
+	ReasonVolumeNotBound      = "VolumeNotBound"
+	ReasonNotCSIVolume        = "NotCSIVolume"
+	ReasonResolved            = "Resolved"
+	ReasonMemberNotFound      = "MemberNotFound"
+	ReasonGroupNotFound       = "GroupNotFound"
+
+	ReasonNoDefaultSnapshotClass         = "NoDefaultSnapshotClass"
+	ReasonMultipleDefaultSnapshotClasses = "MultipleDefaultSnapshotClasses"
+)
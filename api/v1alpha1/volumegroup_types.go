@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,16 +27,104 @@ type VolumeGroupSpec struct {
 	// Selector is a label query over PersistentVolumeClaims that should match the volume group.
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExcludeSelector is a label query over PersistentVolumeClaims that should be excluded from the ones matched by Selector or WorkloadRef.
+	// +optional
+	ExcludeSelector *metav1.LabelSelector `json:"excludeSelector,omitempty"`
+
+	// WorkloadRef refers to a workload whose PersistentVolumeClaims should be in the volume group.
+	// PersistentVolumeClaims used by the workload are added to the ones matched by Selector.
+	// +optional
+	WorkloadRef *WorkloadReference `json:"workloadRef,omitempty"`
+
+	// PersistentVolumeClaimNames is a list of names of PersistentVolumeClaims that should be in the volume group.
+	// PersistentVolumeClaims listed here are added to the ones matched by Selector.
+	// +optional
+	PersistentVolumeClaimNames []string `json:"persistentVolumeClaimNames,omitempty"`
+}
+
+// Kinds of workloads that WorkloadReference can refer to
+const (
+	WorkloadKindStatefulSet = "StatefulSet"
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindPod         = "Pod"
+)
+
+// WorkloadReference refers to a workload in the same namespace
+type WorkloadReference struct {
+	// Kind is the kind of the workload
+	// +kubebuilder:validation:Enum=StatefulSet;Deployment;Pod
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+}
+
+// VolumeGroupStatus defines the observed state of VolumeGroup
+type VolumeGroupStatus struct {
+	// ObservedGeneration is the generation of the VolumeGroup that the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MemberCount is the number of persistent volume claims in the volume group
+	// +optional
+	MemberCount int32 `json:"memberCount"`
+
+	// TotalCapacity is the sum of the requested capacity of all the members
+	// +optional
+	TotalCapacity *resource.Quantity `json:"totalCapacity,omitempty"`
+
+	// Members lists the persistent volume claims in the volume group
+	// +optional
+	Members []VolumeGroupMember `json:"members,omitempty"`
+
+	// MissingPersistentVolumeClaimNames lists the persistent volume claims in PersistentVolumeClaimNames that don't exist
+	// +optional
+	MissingPersistentVolumeClaimNames []string `json:"missingPersistentVolumeClaimNames,omitempty"`
+
+	// Conditions represent the latest available observations of the volume group's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// VolumeGroupMember describes a persistent volume claim in the volume group
+type VolumeGroupMember struct {
+	// PersistentVolumeClaimName is the name of the persistent volume claim
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+
+	// Phase is the phase of the persistent volume claim
+	// +optional
+	Phase corev1.PersistentVolumeClaimPhase `json:"phase,omitempty"`
+
+	// StorageClassName is the name of the StorageClass of the persistent volume claim
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Driver is the name of the CSI driver of the bound persistent volume
+	// +optional
+	Driver *string `json:"driver,omitempty"`
+
+	// Capacity is the requested capacity of the persistent volume claim
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
 }
 
 //+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Members",type=integer,JSONPath=`.status.memberCount`,description="Number of persistent volume claims in the volume group."
+//+kubebuilder:printcolumn:name="Capacity",type=string,JSONPath=`.status.totalCapacity`,description="Total requested capacity of the persistent volume claims in the volume group."
 
 // VolumeGroup is the Schema for the volumegroups API
 type VolumeGroup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VolumeGroupSpec `json:"spec,omitempty"`
+	Spec   VolumeGroupSpec   `json:"spec,omitempty"`
+	Status VolumeGroupStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,19 +28,321 @@ type VolumeGroupSnapshotSpec struct {
 
 	// +optional
 	BoundVolumeGroupSnapshotContentName *string `json:"boundVolumeGroupSnapshotContentName,omitempty"`
+
+	// ClusterVolumeGroupSnapshotContentName is the name of the pre-provisioned
+	// ClusterVolumeGroupSnapshotContent to bind to. The VolumeGroupSnapshotContent
+	// and the VolumeSnapshots for it are created in the namespace of the VolumeGroupSnapshot.
+	// +optional
+	ClusterVolumeGroupSnapshotContentName *string `json:"clusterVolumeGroupSnapshotContentName,omitempty"`
+
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass used to take
+	// snapshots of all the volumes in the group.
+	// If not specified, the default VolumeSnapshotClass for the CSI driver of
+	// each volume is used.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Timeout is the duration from the creation of the VolumeGroupSnapshot within which
+	// all the snapshots need to become ready to use. Otherwise, the group snapshot fails.
+	// If not specified, the default timeout of the controller is used.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// FailurePolicy decides what to do with the VolumeSnapshots already created
+	// when the group snapshot fails.
+	// +kubebuilder:default=Retain
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// IssuanceMode decides how the VolumeSnapshots of the members are created.
+	// +kubebuilder:default=Bounded
+	// +optional
+	IssuanceMode IssuanceMode `json:"issuanceMode,omitempty"`
+
+	// MaxSkew is the maximum CreationTimeSkew for the group snapshot to be crash consistent.
+	// If exceeded, the CrashConsistent condition becomes false.
+	// +optional
+	MaxSkew *metav1.Duration `json:"maxSkew,omitempty"`
+
+	// Hooks are the Jobs run before and after taking the snapshots of the group
+	// +optional
+	Hooks *SnapshotHooks `json:"hooks,omitempty"`
+
+	// Quiesce decides how the applications are quiesced while the snapshots are taken
+	// +kubebuilder:default=None
+	// +optional
+	Quiesce QuiesceMode `json:"quiesce,omitempty"`
+
+	// Workloads are the workloads in the namespace of the VolumeGroupSnapshot scaled down
+	// when Quiesce is ScaleDown. Each of them needs to mount a PersistentVolumeClaim of the group.
+	// +optional
+	Workloads []ScalableWorkloadReference `json:"workloads,omitempty"`
+
+	// DeletionPolicy is copied to the VolumeGroupSnapshotContent created for the VolumeGroup,
+	// and decides whether the VolumeSnapshots are deleted along with the group snapshot.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// QuiesceMode describes how the applications are quiesced while the snapshots are taken
+// +kubebuilder:validation:Enum=None;ScaleDown
+type QuiesceMode string
+
+const (
+	// QuiesceModeNone takes the snapshots without quiescing the applications
+	QuiesceModeNone QuiesceMode = "None"
+
+	// QuiesceModeScaleDown scales the workloads to zero and waits for their Pods to be gone before taking the snapshots,
+	// then restores their replicas once all the snapshots are taken
+	QuiesceModeScaleDown QuiesceMode = "ScaleDown"
+)
+
+// ScalableWorkloadReference refers to a workload in the same namespace that can be scaled down
+type ScalableWorkloadReference struct {
+	// Kind is the kind of the workload
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+}
+
+// WorkloadStatus records a workload scaled down for the group snapshot
+type WorkloadStatus struct {
+	// Kind is the kind of the workload
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+
+	// Replicas is the number of the replicas of the workload before it was scaled down
+	Replicas int32 `json:"replicas"`
+
+	// Restored is true once the replicas of the workload are restored
+	// +optional
+	Restored bool `json:"restored,omitempty"`
+}
+
+// SnapshotHooks describes the Jobs run around the snapshots of the group, such as to freeze and thaw applications.
+// The Pods of the Jobs need to run as a ServiceAccount annotated with HookServiceAccountAnnotation, which defaults to
+// the "default" ServiceAccount, and can't use host namespaces, host ports, hostPath volumes or privileged containers.
+type SnapshotHooks struct {
+	// PreSnapshot is the template of the Job run before taking the snapshots.
+	// The snapshots are taken only after the Job succeeds.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	PreSnapshot *batchv1.JobTemplateSpec `json:"preSnapshot,omitempty"`
+
+	// PostSnapshot is the template of the Job run after all the snapshots are taken.
+	// The Job is run even when the group snapshot fails or is deleted, after the PreSnapshot Job
+	// completes or is stopped. It is skipped if the pre-snapshot hooks are defined but none of them has started.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	PostSnapshot *batchv1.JobTemplateSpec `json:"postSnapshot,omitempty"`
+
+	// PreSnapshotHTTP is the HTTP endpoint called to quiesce the applications before taking the snapshots,
+	// after the PreSnapshot Job succeeds. The snapshots are taken only after the call succeeds.
+	// +optional
+	PreSnapshotHTTP *HTTPHook `json:"preSnapshotHTTP,omitempty"`
+
+	// PostSnapshotHTTP is the HTTP endpoint called to resume the applications after all the snapshots are taken,
+	// before the PostSnapshot Job. The endpoint is called even when the group snapshot fails or is deleted,
+	// so it needs to be idempotent.
+	// +optional
+	PostSnapshotHTTP *HTTPHook `json:"postSnapshotHTTP,omitempty"`
+
+	// Timeout is the duration from the start of each hook within which the hook needs to complete.
+	// Otherwise, the Job is deleted or the HTTP endpoint isn't called anymore, and the hook fails.
+	// If not specified, the pre-snapshot hooks are bounded by the timeout of the group snapshot,
+	// and the PostSnapshot Job, which runs after the group snapshot completes, fails or is deleted, by 10 minutes.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// HTTPHook describes an HTTP(S) endpoint called for a snapshot hook.
+// The endpoint receives a POST request with a JSON payload naming the group snapshot and the phase,
+// and needs to respond with a 2xx status code on success.
+type HTTPHook struct {
+	// URL is the URL of the endpoint, such as the URL of a Service
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Retries is the number of times a failed call is retried
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// RequestTimeout is the timeout of each call. Defaults to 10 seconds.
+	// +optional
+	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+}
+
+// HookName is the name of a snapshot hook
+// +kubebuilder:validation:Enum=PreSnapshot;PostSnapshot;PreSnapshotHTTP;PostSnapshotHTTP
+type HookName string
+
+const (
+	// HookPreSnapshot is the Job run before taking the snapshots
+	HookPreSnapshot HookName = "PreSnapshot"
+
+	// HookPostSnapshot is the Job run after taking the snapshots
+	HookPostSnapshot HookName = "PostSnapshot"
+
+	// HookPreSnapshotHTTP is the HTTP endpoint called before taking the snapshots
+	HookPreSnapshotHTTP HookName = "PreSnapshotHTTP"
+
+	// HookPostSnapshotHTTP is the HTTP endpoint called after taking the snapshots
+	HookPostSnapshotHTTP HookName = "PostSnapshotHTTP"
+)
+
+// HookPhase is the phase of a snapshot hook
+// +kubebuilder:validation:Enum=Running;Succeeded;Failed;Skipped
+type HookPhase string
+
+const (
+	// HookPhaseRunning means the Job of the hook is running, or the HTTP endpoint will be retried
+	HookPhaseRunning HookPhase = "Running"
+
+	// HookPhaseSucceeded means the hook completed successfully
+	HookPhaseSucceeded HookPhase = "Succeeded"
+
+	// HookPhaseFailed means the hook failed or timed out
+	HookPhaseFailed HookPhase = "Failed"
+
+	// HookPhaseSkipped means the post-snapshot hook isn't run, because no pre-snapshot hook has started
+	HookPhaseSkipped HookPhase = "Skipped"
+)
+
+// HookStatus describes the result of a snapshot hook
+type HookStatus struct {
+	// Name is the name of the hook
+	Name HookName `json:"name"`
+
+	// JobName is the name of the Job run for the hook
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Phase is the phase of the hook
+	Phase HookPhase `json:"phase"`
+
+	// StartTime is the time when the Job was created or the HTTP endpoint was first called
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the hook succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Attempts is the number of times the HTTP endpoint was called
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// LastAttemptTime is the time when the HTTP endpoint was last called
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// Message details why the hook failed
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+// FailurePolicy describes what to do with the member VolumeSnapshots of a failed group snapshot
+// +kubebuilder:validation:Enum=Rollback;Retain
+type FailurePolicy string
+
+const (
+	// FailurePolicyRollback deletes all the member VolumeSnapshots created for the group
+	FailurePolicyRollback FailurePolicy = "Rollback"
+
+	// FailurePolicyRetain keeps the member VolumeSnapshots created for the group,
+	// labeled with PartialLabel
+	FailurePolicyRetain FailurePolicy = "Retain"
+)
+
+// IssuanceMode describes how the VolumeSnapshots of the members are created
+// +kubebuilder:validation:Enum=Bounded;Burst
+type IssuanceMode string
+
+const (
+	// IssuanceModeBounded creates the VolumeSnapshots concurrently up to the limit of the controller
+	IssuanceModeBounded IssuanceMode = "Bounded"
+
+	// IssuanceModeBurst prepares all the VolumeSnapshots first, then creates all of them at once
+	// to minimize the skew between the points in time of the members
+	IssuanceModeBurst IssuanceMode = "Burst"
+)
+
+// PartialLabel is the label set to the member VolumeSnapshots retained from a failed group snapshot
+const PartialLabel = "volumegroup.example.com/partial"
+
+// VolumeGroupSnapshotLabel is the label set to the VolumeGroupSnapshotContent created for a VolumeGroupSnapshot,
+// whose value is the name of the VolumeGroupSnapshot
+const VolumeGroupSnapshotLabel = "volumegroup.example.com/volume-group-snapshot"
+
+// VolumeGroupSnapshotContentLabel is the label set to the VolumeSnapshots created for a VolumeGroupSnapshotContent,
+// whose value is the name of the VolumeGroupSnapshotContent
+const VolumeGroupSnapshotContentLabel = "volumegroup.example.com/volume-group-snapshot-content"
+
+// HookServiceAccountAnnotation is the annotation to allow a ServiceAccount to run the hook Jobs when its value is "true".
+// It keeps the users who can create VolumeGroupSnapshots from running arbitrary Pods through the controller.
+const HookServiceAccountAnnotation = "volumegroup.example.com/allow-snapshot-hooks"
+
+// ClusterVolumeGroupSnapshotContentLabel is the label set to the VolumeGroupSnapshotContent created for a
+// ClusterVolumeGroupSnapshotContent, whose value is the name of the ClusterVolumeGroupSnapshotContent
+const ClusterVolumeGroupSnapshotContentLabel = "volumegroup.example.com/cluster-volume-group-snapshot-content"
+
 // VolumeGroupSnapshotStatus defines the observed state of VolumeGroupSnapshot
 type VolumeGroupSnapshotStatus struct {
 	// ReadyToUse becomes true when ReadyToUse on all individual snapshots become true
 	// +optional
 	ReadyToUse *bool `json:"readyToUse,omitempty"`
 
+	// CreationTime is the earliest CreationTime among the member snapshots.
+	// All the writes completed before this time are contained in every member snapshot.
+	// It is set once all the member snapshots are taken.
 	// +optional
 	CreationTime *metav1.Time `json:"creationTime,omitempty"`
 
+	// LatestCreationTime is the latest CreationTime among the member snapshots
+	// +optional
+	LatestCreationTime *metav1.Time `json:"latestCreationTime,omitempty"`
+
+	// CreationTimeSkew is the difference between LatestCreationTime and CreationTime
+	// +optional
+	CreationTimeSkew *metav1.Duration `json:"creationTimeSkew,omitempty"`
+
+	// IssuanceSkew is the difference between the times when the first and the last VolumeSnapshots
+	// were created, measured by the controller
+	// +optional
+	IssuanceSkew *metav1.Duration `json:"issuanceSkew,omitempty"`
+
+	// Error is the error propagated from the VolumeGroupSnapshotContent
 	// +optional
 	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
+
+	// Hooks are the results of the hooks propagated from the VolumeGroupSnapshotContent
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Hooks []HookStatus `json:"hooks,omitempty"`
+
+	// ObservedGeneration is the generation observed when the status was last updated
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the group snapshot's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // VolumeGroupSnapshotError describes an error encountered on the group snapshot
@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // VolumeGroupSnapshotContentSpec defines the desired state of VolumeGroupSnapshotContent
@@ -27,6 +29,12 @@ type VolumeGroupSnapshotContentSpec struct {
 	// to which this VolumeGroupSnapshotContent object is bound.
 	VolumeGroupSnapshotName *string `json:"volumeGroupSnapshotName,omitempty"`
 
+	// VolumeGroupSnapshotUID is the UID of the VolumeGroupSnapshot bound to this VolumeGroupSnapshotContent.
+	// If not specified, it is set when the VolumeGroupSnapshot named by VolumeGroupSnapshotName binds to it,
+	// so that no other VolumeGroupSnapshot can bind to it afterwards.
+	// +optional
+	VolumeGroupSnapshotUID types.UID `json:"volumeGroupSnapshotUID,omitempty"`
+
 	// List of persistent volume claims to take snapshots from
 	// +optional
 	PersistentVolumeClaimList []string `json:"persistentVolumeClaimList"`
@@ -34,17 +42,171 @@ type VolumeGroupSnapshotContentSpec struct {
 	// Required
 	// List of volume snapshots
 	SnapshotList []string `json:"snapshotList"`
+
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass set to
+	// the VolumeSnapshots created for the persistent volume claims.
+	// If not specified, the default VolumeSnapshotClass for the CSI driver of
+	// each persistent volume claim is chosen.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Timeout is the duration from the creation of the VolumeGroupSnapshotContent within which
+	// all the snapshots need to become ready to use. Otherwise, the group snapshot fails.
+	// If not specified, the default timeout of the controller is used.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Deadline is the time by which all the snapshots need to become ready to use.
+	// It is set to the deadline of the VolumeGroupSnapshot that the VolumeGroupSnapshotContent
+	// is created for, and takes precedence over Timeout.
+	// +optional
+	Deadline *metav1.Time `json:"deadline,omitempty"`
+
+	// FailurePolicy decides what to do with the VolumeSnapshots already created
+	// when the group snapshot fails.
+	// +kubebuilder:default=Retain
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// IssuanceMode decides how the VolumeSnapshots of the members are created.
+	// +kubebuilder:default=Bounded
+	// +optional
+	IssuanceMode IssuanceMode `json:"issuanceMode,omitempty"`
+
+	// MaxSkew is the maximum CreationTimeSkew for the group snapshot to be crash consistent.
+	// If exceeded, the CrashConsistent condition becomes false.
+	// +optional
+	MaxSkew *metav1.Duration `json:"maxSkew,omitempty"`
+
+	// Hooks are the Jobs run before and after taking the snapshots of the group
+	// +optional
+	Hooks *SnapshotHooks `json:"hooks,omitempty"`
+
+	// Quiesce decides how the applications are quiesced while the snapshots are taken
+	// +kubebuilder:default=None
+	// +optional
+	Quiesce QuiesceMode `json:"quiesce,omitempty"`
+
+	// Workloads are the workloads in the namespace of the VolumeGroupSnapshotContent scaled down
+	// when Quiesce is ScaleDown. Each of them needs to mount a PersistentVolumeClaim of the group.
+	// +optional
+	Workloads []ScalableWorkloadReference `json:"workloads,omitempty"`
+
+	// DeletionPolicy decides whether the VolumeSnapshots in SnapshotList are deleted
+	// when the VolumeGroupSnapshotContent is deleted.
+	// VolumeGroupSnapshotContents created for VolumeGroupSnapshots take DeletionPolicy of the VolumeGroupSnapshot.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
+// DeletionPolicy describes what happens to the VolumeSnapshots when the VolumeGroupSnapshotContent is deleted
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the VolumeSnapshots along with the VolumeGroupSnapshotContent
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyRetain keeps the VolumeSnapshots after the VolumeGroupSnapshotContent is deleted
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // VolumeGroupSnapshotContentStatus defines the observed state of VolumeGroupSnapshotContent
 type VolumeGroupSnapshotContentStatus struct {
 	// ReadyToUse becomes true when ReadyToUse on all individual snapshots become true
 	// +optional
 	ReadyToUse *bool `json:"readyToUse,omitempty"`
 
+	// CreationTime is the earliest CreationTime among the member snapshots.
+	// All the writes completed before this time are contained in every member snapshot.
+	// It is set once all the member snapshots are taken.
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// LatestCreationTime is the latest CreationTime among the member snapshots
+	// +optional
+	LatestCreationTime *metav1.Time `json:"latestCreationTime,omitempty"`
+
+	// CreationTimeSkew is the difference between LatestCreationTime and CreationTime
+	// +optional
+	CreationTimeSkew *metav1.Duration `json:"creationTimeSkew,omitempty"`
+
+	// IssuanceSkew is the difference between the times when the first and the last VolumeSnapshots
+	// were created, measured by the controller.
+	// It is set only when all the VolumeSnapshots are created at once.
+	// +optional
+	IssuanceSkew *metav1.Duration `json:"issuanceSkew,omitempty"`
+
+	// Error is the error reported by a member VolumeSnapshot
+	// +optional
+	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
+
+	// Members lists the observed state of the snapshot of each member of the group
+	// +optional
+	Members []VolumeGroupSnapshotMemberStatus `json:"members,omitempty"`
+
+	// Hooks are the results of the hooks run for the group snapshot
 	// +optional
-	CreationTime *int64 `json:"creationTime,omitempty"`
+	// +listType=map
+	// +listMapKey=name
+	Hooks []HookStatus `json:"hooks,omitempty"`
+
+	// Workloads records the replicas of the workloads before they were scaled down,
+	// so that they are restored even if the controller restarts in the middle of the group snapshot
+	// +optional
+	// +listType=map
+	// +listMapKey=kind
+	// +listMapKey=name
+	Workloads []WorkloadStatus `json:"workloads,omitempty"`
+
+	// ObservedGeneration is the generation observed when the status was last updated
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the group snapshot content's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// VolumeGroupSnapshotMemberStatus describes the observed state of the snapshot of a member of the group
+type VolumeGroupSnapshotMemberStatus struct {
+	// PersistentVolumeClaimName is the name of the persistent volume claim the snapshot was taken from
+	// +optional
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName,omitempty"`
+
+	// VolumeSnapshotName is the name of the VolumeSnapshot
+	VolumeSnapshotName string `json:"volumeSnapshotName"`
+
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass of the VolumeSnapshot
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// VolumeSnapshotContentName is the name of the VolumeSnapshotContent bound to the VolumeSnapshot
+	// +optional
+	VolumeSnapshotContentName *string `json:"volumeSnapshotContentName,omitempty"`
+
+	// SnapshotHandle is the CSI snapshot handle of the VolumeSnapshotContent
+	// +optional
+	SnapshotHandle *string `json:"snapshotHandle,omitempty"`
+
+	// RestoreSize is the minimum size of a volume to restore the snapshot to
+	// +optional
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty"`
+
+	// CreationTime is the time when the snapshot was taken by the storage system
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// ReadyToUse is ReadyToUse of the VolumeSnapshot
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty"`
 
+	// Error is the error reported by the VolumeSnapshot
 	// +optional
 	Error *VolumeGroupSnapshotError `json:"error,omitempty"`
 }
@@ -54,6 +216,7 @@ type VolumeGroupSnapshotContentStatus struct {
 //+kubebuilder:resource:scope=Namespaced,shortName=vgsc
 //+kubebuilder:printcolumn:name="ReadyToUse",type=boolean,JSONPath=`.status.readyToUse`,description="Indicates if the volumeGroupSnapshotContent is ready to be used to restore a volume."
 //+kubebuilder:printcolumn:name="VolumeGroupSnapshot",type=string,JSONPath=`.spec.volumeGroupSnapshotName`,description="Name of the VolumeGroupSnapshot object to which this VolumeGroupSnapshotContent object is bound."
+//+kubebuilder:printcolumn:name="DeletionPolicy",type=string,JSONPath=`.spec.deletionPolicy`,description="Determines whether the VolumeSnapshots of this VolumeGroupSnapshotContent should be deleted when the VolumeGroupSnapshotContent is deleted."
 
 // VolumeGroupSnapshotContent is the Schema for the volumegroupsnapshotcontents API
 type VolumeGroupSnapshotContent struct {
@@ -0,0 +1,588 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// clusterVolumeGroupSnapshotFinalizer is the finalizer to delete the VolumeSnapshots before the ClusterVolumeGroupSnapshot
+const clusterVolumeGroupSnapshotFinalizer = "volumegroup.example.com/clustervolumegroupsnapshot-protection"
+
+// ClusterVolumeGroupSnapshotReconciler reconciles a ClusterVolumeGroupSnapshot object
+type ClusterVolumeGroupSnapshotReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// DefaultTimeout is the timeout of the group snapshots that don't specify one. Zero means no timeout.
+	DefaultTimeout time.Duration
+
+	// MaxConcurrentSnapshotCreations is the maximum number of VolumeSnapshots created at the same time for a group
+	MaxConcurrentSnapshotCreations int
+}
+
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshots,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshots/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshots/finalizers,verbs=update
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+
+// Reconcile is reconciliation loop for ClusterVolumeGroupSnapshot
+func (r *ClusterVolumeGroupSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	cvgs := &volumegroupv1alpha1.ClusterVolumeGroupSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, cvgs); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found. Ignore this
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !cvgs.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, cvgs)
+	}
+
+	if !controllerutil.ContainsFinalizer(cvgs, clusterVolumeGroupSnapshotFinalizer) {
+		controllerutil.AddFinalizer(cvgs, clusterVolumeGroupSnapshotFinalizer)
+		if err := r.Update(ctx, cvgs); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if cvgs.Status.ReadyToUse != nil && *cvgs.Status.ReadyToUse {
+		// Already ready to use
+		return ctrl.Result{}, nil
+	}
+
+	if meta.IsStatusConditionTrue(cvgs.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Already failed
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := cvgs.Status.DeepCopy()
+
+	if deadline, ok := deadlineFor(cvgs, cvgs.Spec.Timeout, r.DefaultTimeout); ok && !time.Now().Before(deadline) {
+		setClusterGroupSnapshotFailed(cvgs, volumegroupv1alpha1.ReasonTimeout,
+			fmt.Sprintf("ClusterVolumeGroupSnapshot did not become ready to use by %s", deadline.Format(time.RFC3339)))
+		if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Timed out group snapshot won't be retried
+		return ctrl.Result{}, nil
+	}
+
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionFalse,
+		volumegroupv1alpha1.ReasonNoFailure, "No VolumeSnapshot has failed")
+
+	if len(cvgs.Status.Members) == 0 {
+		// Fix the members across all the namespaces before creating any VolumeSnapshot
+		if err := r.resolveMembers(ctx, cvgs); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Status update triggers the next reconciliation, and creation of the PersistentVolumeClaims is watched
+		return requeueAtDeadline(cvgs, cvgs.Spec.Timeout, r.DefaultTimeout), nil
+	}
+
+	// Create VolumeSnapshots for all the members as one set
+	conflict, err := r.createVolumeSnapshots(ctx, cvgs)
+	if err != nil {
+		if classErr, ok := asSnapshotClassError(err); ok {
+			setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+				classErr.reason, classErr.message)
+			setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+				classErr.reason, classErr.message)
+			if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, err
+	}
+
+	if conflict != "" {
+		setClusterGroupSnapshotFailed(cvgs, volumegroupv1alpha1.ReasonSnapshotConflict, conflict)
+		if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Conflicting VolumeSnapshot won't be adopted, so stop retrying
+		return ctrl.Result{}, nil
+	}
+
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonCreated, fmt.Sprintf("%d VolumeSnapshots are created", len(cvgs.Status.Members)))
+
+	// Update ReadyToUse
+	readyToUse, err := r.updateReadyToUse(ctx, cvgs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !readyToUse && !meta.IsStatusConditionTrue(cvgs.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Progress of the owned VolumeSnapshots is watched
+		return requeueAtDeadline(cvgs, cvgs.Spec.Timeout, r.DefaultTimeout), nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// resolveMembers fixes the members of the ClusterVolumeGroupSnapshot, or reports that the ClusterVolumeGroup has none yet
+func (r *ClusterVolumeGroupSnapshotReconciler) resolveMembers(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot) error {
+	cvg := &volumegroupv1alpha1.ClusterVolumeGroup{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cvgs.Spec.ClusterVolumeGroupName}, cvg); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		// Creation of the ClusterVolumeGroup is watched
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionMembersResolved, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonGroupNotFound, fmt.Sprintf("ClusterVolumeGroup %s is not found", cvgs.Spec.ClusterVolumeGroupName))
+		return nil
+	}
+
+	pvcs, err := clusterVolumeGroupMembers(ctx, r.Client, cvg)
+	if err != nil {
+		return err
+	}
+
+	if len(pvcs) == 0 {
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionMembersResolved, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonMemberNotFound, fmt.Sprintf("No PersistentVolumeClaims found for ClusterVolumeGroup %s", cvg.Name))
+		return nil
+	}
+
+	members := []volumegroupv1alpha1.ClusterVolumeGroupSnapshotMember{}
+	for _, pvc := range pvcs {
+		members = append(members, volumegroupv1alpha1.ClusterVolumeGroupSnapshotMember{
+			Namespace:                 pvc.Namespace,
+			PersistentVolumeClaimName: pvc.Name,
+			VolumeSnapshotName:        generatedName("vs", cvgs.Name, pvc.Name),
+		})
+	}
+
+	cvgs.Status.Members = members
+	cvgs.Status.Namespaces = summarizeNamespaces(members)
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionMembersResolved, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonResolved, fmt.Sprintf("%d PersistentVolumeClaims in %d namespaces are resolved", len(members), len(cvgs.Status.Namespaces)))
+
+	return nil
+}
+
+// clusterVolumeGroupMembers returns the PersistentVolumeClaims that belong to the ClusterVolumeGroup, sorted by namespace and name
+func clusterVolumeGroupMembers(ctx context.Context, c client.Client, cvg *volumegroupv1alpha1.ClusterVolumeGroup) ([]corev1.PersistentVolumeClaim, error) {
+	pvcs := []corev1.PersistentVolumeClaim{}
+
+	if cvg.Spec.NamespaceSelector == nil || cvg.Spec.Selector == nil {
+		// A nil selector matches nothing
+		return pvcs, nil
+	}
+
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(cvg.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(cvg.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := c.List(ctx, nsList, client.MatchingLabelsSelector{Selector: namespaceSelector}); err != nil {
+		return nil, err
+	}
+
+	for _, ns := range nsList.Items {
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		if err := c.List(ctx, pvcList, client.InNamespace(ns.Name), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		pvcs = append(pvcs, pvcList.Items...)
+	}
+
+	sort.Slice(pvcs, func(i, j int) bool {
+		if pvcs[i].Namespace != pvcs[j].Namespace {
+			return pvcs[i].Namespace < pvcs[j].Namespace
+		}
+		return pvcs[i].Name < pvcs[j].Name
+	})
+
+	return pvcs, nil
+}
+
+// createVolumeSnapshots creates the missing VolumeSnapshots of the members.
+// It returns the reason if an existing VolumeSnapshot wasn't created for the ClusterVolumeGroupSnapshot.
+func (r *ClusterVolumeGroupSnapshotReconciler) createVolumeSnapshots(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot) (string, error) {
+	// Prepare all the missing VolumeSnapshots first so that they are created as close together as possible
+	snapshots := []*snapshotv1.VolumeSnapshot{}
+	for _, member := range cvgs.Status.Members {
+		pvcName := member.PersistentVolumeClaimName
+		source := snapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: &pvcName}
+
+		existing := &snapshotv1.VolumeSnapshot{}
+		err := r.Get(ctx, types.NamespacedName{Name: member.VolumeSnapshotName, Namespace: member.Namespace}, existing)
+		if err == nil {
+			// Only adopt the VolumeSnapshot created for this group
+			if conflict := volumeSnapshotConflict(cvgs, "ClusterVolumeGroupSnapshot", existing, source); conflict != "" {
+				return conflict, nil
+			}
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return "", err
+		}
+
+		vs, err := r.volumeSnapshotFor(ctx, cvgs, member)
+		if err != nil {
+			return "", err
+		}
+		snapshots = append(snapshots, vs)
+	}
+
+	parallelism := r.MaxConcurrentSnapshotCreations
+	if parallelism <= 0 {
+		parallelism = defaultMaxConcurrentSnapshotCreations
+	}
+
+	_, conflicts, errs := createConcurrently(len(snapshots), parallelism, func(i int) (string, error) {
+		return r.createVolumeSnapshot(ctx, cvgs, snapshots[i])
+	})
+
+	for i := range snapshots {
+		if errs[i] != nil {
+			return "", errs[i]
+		}
+	}
+	for i := range snapshots {
+		if conflicts[i] != "" {
+			return conflicts[i], nil
+		}
+	}
+
+	return "", nil
+}
+
+// createVolumeSnapshot creates the VolumeSnapshot unless it already exists.
+// It returns the reason if the existing VolumeSnapshot wasn't created for the ClusterVolumeGroupSnapshot.
+func (r *ClusterVolumeGroupSnapshotReconciler) createVolumeSnapshot(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot, vs *snapshotv1.VolumeSnapshot) (string, error) {
+	if err := r.Create(ctx, vs); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return "", err
+		}
+
+		// Only adopt the VolumeSnapshot created for this group
+		existing := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vs.Name, Namespace: vs.Namespace}, existing); err != nil {
+			return "", err
+		}
+		return volumeSnapshotConflict(cvgs, "ClusterVolumeGroupSnapshot", existing, vs.Spec.Source), nil
+	}
+
+	return "", nil
+}
+
+func (r *ClusterVolumeGroupSnapshotReconciler) volumeSnapshotFor(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot, member volumegroupv1alpha1.ClusterVolumeGroupSnapshotMember) (*snapshotv1.VolumeSnapshot, error) {
+	className := cvgs.Spec.VolumeSnapshotClassName
+	if className == nil {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Name: member.PersistentVolumeClaimName, Namespace: member.Namespace}, pvc); err != nil {
+			return nil, err
+		}
+
+		var err error
+		className, err = defaultVolumeSnapshotClassFor(ctx, r.Client, pvc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pvcName := member.PersistentVolumeClaimName
+	vs := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      member.VolumeSnapshotName,
+			Namespace: member.Namespace,
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: &pvcName,
+			},
+			VolumeSnapshotClassName: className,
+		},
+	}
+
+	// Cluster-scoped owner is allowed for namespaced VolumeSnapshots
+	if err := ctrl.SetControllerReference(cvgs, vs, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return vs, nil
+}
+
+func (r *ClusterVolumeGroupSnapshotReconciler) updateReadyToUse(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot) (bool, error) {
+	notReady := 0
+	missing := []string{}
+	failed := []*snapshotv1.VolumeSnapshot{}
+	for i := range cvgs.Status.Members {
+		member := &cvgs.Status.Members[i]
+		vs := &snapshotv1.VolumeSnapshot{}
+
+		if err := r.Get(ctx, types.NamespacedName{Name: member.VolumeSnapshotName, Namespace: member.Namespace}, vs); err != nil {
+			if !errors.IsNotFound(err) {
+				return false, err
+			}
+
+			// Not in the cache yet, or deleted and created again on the next reconciliation
+			ready := false
+			member.ReadyToUse = &ready
+			member.Error = nil
+			notReady++
+			missing = append(missing, fmt.Sprintf("%s/%s", member.Namespace, member.VolumeSnapshotName))
+			continue
+		}
+
+		ready := vs.Status != nil && vs.Status.ReadyToUse != nil && *vs.Status.ReadyToUse
+		member.ReadyToUse = &ready
+		if !ready {
+			notReady++
+		}
+
+		member.Error = nil
+		if vs.Status != nil && vs.Status.Error != nil {
+			member.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{
+				Time:    vs.Status.Error.Time,
+				Message: vs.Status.Error.Message,
+			}
+			failed = append(failed, vs)
+		}
+	}
+
+	allReady := notReady == 0
+	cvgs.Status.Namespaces = summarizeNamespaces(cvgs.Status.Members)
+	cvgs.Status.ReadyToUse = &allReady
+
+	if len(failed) > 0 {
+		cvgs.Status.Error = groupSnapshotErrorFor(failed)
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonSnapshotFailed, *cvgs.Status.Error.Message)
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonSnapshotFailed, fmt.Sprintf("%d of %d VolumeSnapshots have failed", len(failed), len(cvgs.Status.Members)))
+		return false, nil
+	}
+
+	if len(missing) > 0 {
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonSnapshotNotFound, fmt.Sprintf("VolumeSnapshots %s are not found", strings.Join(missing, ", ")))
+		return false, nil
+	}
+
+	if !allReady {
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForSnapshots, fmt.Sprintf("%d of %d VolumeSnapshots are not ready to use", notReady, len(cvgs.Status.Members)))
+		return false, nil
+	}
+
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonReady, "All VolumeSnapshots are ready to use")
+	return true, nil
+}
+
+// setClusterGroupSnapshotFailed marks the ClusterVolumeGroupSnapshot as failed for the reason
+func setClusterGroupSnapshotFailed(cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot, reason, message string) {
+	cvgs.Status.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{Time: &metav1.Time{Time: time.Now()}, Message: &message}
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+		reason, message)
+	setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+		reason, message)
+}
+
+// finalize deletes the VolumeSnapshots of the ClusterVolumeGroupSnapshot, then removes the finalizer once they are gone
+func (r *ClusterVolumeGroupSnapshotReconciler) finalize(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cvgs, clusterVolumeGroupSnapshotFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := cvgs.Status.DeepCopy()
+
+	blocking := ""
+	for _, member := range cvgs.Status.Members {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: member.VolumeSnapshotName, Namespace: member.Namespace}, vs); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, err
+		}
+
+		if !metav1.IsControlledBy(vs, cvgs) {
+			// Not created for this ClusterVolumeGroupSnapshot, so leave it as it is
+			continue
+		}
+
+		if vs.DeletionTimestamp.IsZero() {
+			if err := r.Delete(ctx, vs); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if blocking == "" {
+			blocking = fmt.Sprintf("VolumeSnapshot %s/%s is being deleted", vs.Namespace, vs.Name)
+			if vs.Status != nil && vs.Status.Error != nil && vs.Status.Error.Message != nil {
+				blocking = fmt.Sprintf("%s: %s", blocking, *vs.Status.Error.Message)
+			}
+		}
+	}
+
+	if blocking != "" {
+		setCondition(&cvgs.Status.Conditions, cvgs.Generation, volumegroupv1alpha1.ConditionDeleting, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonDeletingSnapshots, blocking)
+		if err := r.updateStatus(ctx, cvgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Deletion of the owned VolumeSnapshots is watched
+		return ctrl.Result{}, nil
+	}
+
+	controllerutil.RemoveFinalizer(cvgs, clusterVolumeGroupSnapshotFinalizer)
+	if err := r.Update(ctx, cvgs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// updateStatus updates the status of the ClusterVolumeGroupSnapshot only if it differs from the original
+func (r *ClusterVolumeGroupSnapshotReconciler) updateStatus(ctx context.Context, cvgs *volumegroupv1alpha1.ClusterVolumeGroupSnapshot, original *volumegroupv1alpha1.ClusterVolumeGroupSnapshotStatus) error {
+	cvgs.Status.ObservedGeneration = cvgs.Generation
+
+	if equality.Semantic.DeepEqual(original, &cvgs.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, cvgs)
+}
+
+// summarizeNamespaces aggregates the members per namespace
+func summarizeNamespaces(members []volumegroupv1alpha1.ClusterVolumeGroupSnapshotMember) []volumegroupv1alpha1.ClusterVolumeGroupSnapshotNamespace {
+	namespaces := []volumegroupv1alpha1.ClusterVolumeGroupSnapshotNamespace{}
+	index := map[string]int{}
+
+	for _, member := range members {
+		i, ok := index[member.Namespace]
+		if !ok {
+			i = len(namespaces)
+			index[member.Namespace] = i
+			namespaces = append(namespaces, volumegroupv1alpha1.ClusterVolumeGroupSnapshotNamespace{Namespace: member.Namespace})
+		}
+
+		namespaces[i].MemberCount++
+		if member.ReadyToUse != nil && *member.ReadyToUse {
+			namespaces[i].ReadyCount++
+		}
+	}
+
+	return namespaces
+}
+
+// clusterVolumeGroupSnapshotsForPVC maps a PersistentVolumeClaim to the ClusterVolumeGroupSnapshots
+// that have yet to resolve their members
+func (r *ClusterVolumeGroupSnapshotReconciler) clusterVolumeGroupSnapshotsForPVC(obj client.Object) []reconcile.Request {
+	cvgsList := &volumegroupv1alpha1.ClusterVolumeGroupSnapshotList{}
+	if err := r.List(context.Background(), cvgsList); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, cvgs := range cvgsList.Items {
+		if len(cvgs.Status.Members) > 0 {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: cvgs.Name},
+		})
+	}
+
+	return requests
+}
+
+// clusterVolumeGroupSnapshotsForGroup maps a ClusterVolumeGroup to the ClusterVolumeGroupSnapshots
+// of it that have yet to resolve their members
+func (r *ClusterVolumeGroupSnapshotReconciler) clusterVolumeGroupSnapshotsForGroup(obj client.Object) []reconcile.Request {
+	cvgsList := &volumegroupv1alpha1.ClusterVolumeGroupSnapshotList{}
+	if err := r.List(context.Background(), cvgsList); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, cvgs := range cvgsList.Items {
+		if cvgs.Spec.ClusterVolumeGroupName != obj.GetName() || len(cvgs.Status.Members) > 0 {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: cvgs.Name},
+		})
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterVolumeGroupSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&volumegroupv1alpha1.ClusterVolumeGroupSnapshot{}).
+		Owns(&snapshotv1.VolumeSnapshot{}).
+		Watches(&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterVolumeGroupSnapshotsForPVC)).
+		Watches(&source.Kind{Type: &volumegroupv1alpha1.ClusterVolumeGroup{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterVolumeGroupSnapshotsForGroup)).
+		Complete(r)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestClusterGroupSnapshot returns a ClusterVolumeGroupSnapshot of the ClusterVolumeGroup protected by the finalizer
+func newTestClusterGroupSnapshot() *volumegroupv1alpha1.ClusterVolumeGroupSnapshot {
+	className := "class"
+	return &volumegroupv1alpha1.ClusterVolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "cvgs",
+			UID:        "cvgs-uid",
+			Generation: 1,
+			Finalizers: []string{clusterVolumeGroupSnapshotFinalizer},
+		},
+		Spec: volumegroupv1alpha1.ClusterVolumeGroupSnapshotSpec{
+			ClusterVolumeGroupName:  "cvg",
+			VolumeSnapshotClassName: &className,
+		},
+	}
+}
+
+// newTestClusterGroup returns a ClusterVolumeGroup with the PersistentVolumeClaims it selects in the namespaces
+func newTestClusterGroup(namespaces []string, pvcsPerNamespace int) []client.Object {
+	objs := []client.Object{&volumegroupv1alpha1.ClusterVolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cvg"},
+		Spec: volumegroupv1alpha1.ClusterVolumeGroupSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+	}}
+
+	for _, ns := range namespaces {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: map[string]string{"team": "a"}}})
+		for i := 0; i < pvcsPerNamespace; i++ {
+			objs = append(objs, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("pvc-%d", i), Namespace: ns, Labels: map[string]string{"app": "db"},
+			}})
+		}
+	}
+
+	return objs
+}
+
+func TestReconcileClusterGroupSnapshotWaitsForGroup(t *testing.T) {
+	cvgs := newTestClusterGroupSnapshot()
+	c := newFakeClient(t, cvgs)
+	r := &ClusterVolumeGroupSnapshotReconciler{Client: c, Scheme: c.Scheme()}
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cvgs)}); err != nil {
+		t.Fatalf("expected the missing ClusterVolumeGroup to be reported instead of an error, got %v", err)
+	}
+
+	getObject(t, c, cvgs)
+	resolved := meta.FindStatusCondition(cvgs.Status.Conditions, volumegroupv1alpha1.ConditionMembersResolved)
+	if resolved == nil || resolved.Status != metav1.ConditionFalse || resolved.Reason != volumegroupv1alpha1.ReasonGroupNotFound {
+		t.Fatalf("expected the members not to be resolved for %s, got %+v", volumegroupv1alpha1.ReasonGroupNotFound, resolved)
+	}
+	if meta.IsStatusConditionTrue(cvgs.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		t.Fatal("expected the group snapshot not to fail")
+	}
+
+	// Creation of the ClusterVolumeGroup resumes the group snapshot
+	cvg := &volumegroupv1alpha1.ClusterVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "cvg"}}
+	if requests := r.clusterVolumeGroupSnapshotsForGroup(cvg); len(requests) != 1 || requests[0].Name != cvgs.Name {
+		t.Fatalf("expected the group snapshot to be reconciled, got %v", requests)
+	}
+	cvg.Name = "other"
+	if requests := r.clusterVolumeGroupSnapshotsForGroup(cvg); len(requests) != 0 {
+		t.Fatalf("expected no group snapshot of the other group to be reconciled, got %v", requests)
+	}
+}
+
+func TestReconcileClusterGroupSnapshotCreatesSnapshotsConcurrently(t *testing.T) {
+	cvgs := newTestClusterGroupSnapshot()
+	c := newFakeClient(t, append(newTestClusterGroup([]string{"ns-a", "ns-b"}, 3), cvgs)...)
+	counter := &concurrencyClient{Client: c, delay: 20 * time.Millisecond}
+	r := &ClusterVolumeGroupSnapshotReconciler{Client: counter, Scheme: c.Scheme(), MaxConcurrentSnapshotCreations: 2}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cvgs)}
+	ctx := context.Background()
+
+	// Members are resolved first, then their VolumeSnapshots are created
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if counter.created != 6 {
+		t.Fatalf("expected 6 VolumeSnapshots to be created, got %d", counter.created)
+	}
+	if counter.max != 2 {
+		t.Fatalf("expected 2 VolumeSnapshots to be created at the same time, got %d", counter.max)
+	}
+
+	getObject(t, c, cvgs)
+	for _, member := range cvgs.Status.Members {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := c.Get(ctx, client.ObjectKey{Name: member.VolumeSnapshotName, Namespace: member.Namespace}, vs); err != nil {
+			t.Fatal(err)
+		}
+		if !metav1.IsControlledBy(vs, cvgs) {
+			t.Fatalf("expected VolumeSnapshot %s/%s to be controlled by the group snapshot", vs.Namespace, vs.Name)
+		}
+	}
+}
+
+func TestUpdateReadyToUseWaitsForMissingSnapshots(t *testing.T) {
+	cvgs := newTestClusterGroupSnapshot()
+	cvgs.Status.Members = []volumegroupv1alpha1.ClusterVolumeGroupSnapshotMember{
+		{Namespace: "ns-a", PersistentVolumeClaimName: "pvc-0", VolumeSnapshotName: "vs-0"},
+		{Namespace: "ns-b", PersistentVolumeClaimName: "pvc-0", VolumeSnapshotName: "vs-0"},
+	}
+	ready := true
+	vs := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "vs-0", Namespace: "ns-a"},
+		Status:     &snapshotv1.VolumeSnapshotStatus{ReadyToUse: &ready},
+	}
+
+	r := &ClusterVolumeGroupSnapshotReconciler{Client: newFakeClient(t, cvgs, vs)}
+	readyToUse, err := r.updateReadyToUse(context.Background(), cvgs)
+	if err != nil {
+		t.Fatalf("expected the missing VolumeSnapshot to be waited for instead of an error, got %v", err)
+	}
+	if readyToUse {
+		t.Fatal("expected the group snapshot not to be ready to use")
+	}
+
+	cond := meta.FindStatusCondition(cvgs.Status.Conditions, volumegroupv1alpha1.ConditionReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != volumegroupv1alpha1.ReasonSnapshotNotFound {
+		t.Fatalf("expected the group snapshot to wait for %s, got %+v", volumegroupv1alpha1.ReasonSnapshotNotFound, cond)
+	}
+	if cvgs.Status.Namespaces[0].ReadyCount != 1 || cvgs.Status.Namespaces[1].ReadyCount != 0 {
+		t.Fatalf("expected only the existing VolumeSnapshot to be ready, got %+v", cvgs.Status.Namespaces)
+	}
+}
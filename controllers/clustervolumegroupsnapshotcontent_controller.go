@@ -0,0 +1,456 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// clusterVolumeGroupSnapshotContentFinalizer is the finalizer to enforce the DeletionPolicy of ClusterVolumeGroupSnapshotContent
+const clusterVolumeGroupSnapshotContentFinalizer = "volumegroup.example.com/clustervolumegroupsnapshotcontent-protection"
+
+// ClusterVolumeGroupSnapshotContentReconciler reconciles a ClusterVolumeGroupSnapshotContent object
+type ClusterVolumeGroupSnapshotContentReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshotcontents,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshotcontents/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=clustervolumegroupsnapshotcontents/finalizers,verbs=update
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshots,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents,verbs=get;list;watch;create;patch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotcontents,verbs=get;list;watch;delete
+
+// Reconcile is reconciliation loop for ClusterVolumeGroupSnapshotContent
+func (r *ClusterVolumeGroupSnapshotContentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	cvgsc := &volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent{}
+	if err := r.Get(ctx, req.NamespacedName, cvgsc); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found. Ignore this
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !cvgsc.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, cvgsc)
+	}
+
+	if !controllerutil.ContainsFinalizer(cvgsc, clusterVolumeGroupSnapshotContentFinalizer) {
+		controllerutil.AddFinalizer(cvgsc, clusterVolumeGroupSnapshotContentFinalizer)
+		if err := r.Update(ctx, cvgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if cvgsc.Status.ReadyToUse != nil && *cvgsc.Status.ReadyToUse {
+		// Already ready to use
+		return ctrl.Result{}, nil
+	}
+
+	if meta.IsStatusConditionTrue(cvgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Failure of the VolumeGroupSnapshotContent is final
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := cvgsc.Status.DeepCopy()
+
+	ref := cvgsc.Spec.VolumeGroupSnapshotRef
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, vgs); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionSnapshotBound, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonSnapshotNotFound, fmt.Sprintf("VolumeGroupSnapshot %s/%s is not found", ref.Namespace, ref.Name))
+		if err := r.updateStatus(ctx, cvgsc, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Creation of the VolumeGroupSnapshot is watched
+		return ctrl.Result{}, nil
+	}
+
+	// Verify that the VolumeGroupSnapshot refers back to this ClusterVolumeGroupSnapshotContent
+	if message := snapshotBindingError(cvgsc, vgs); message != "" {
+		return r.reportNotBound(ctx, cvgsc, originalStatus, volumegroupv1alpha1.ReasonBindingMismatch, message)
+	}
+
+	if cvgsc.Spec.VolumeGroupSnapshotRef.UID == "" {
+		// Claim the ClusterVolumeGroupSnapshotContent, which fails on conflict with another claim
+		cvgsc.Spec.VolumeGroupSnapshotRef.UID = vgs.UID
+		if err := r.Update(ctx, cvgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	reason, message, err := r.validateVolumeSnapshotContents(ctx, cvgsc, vgs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if message != "" {
+		return r.reportNotBound(ctx, cvgsc, originalStatus, reason, message)
+	}
+
+	// Create VolumeGroupSnapshotContent and VolumeSnapshots in the namespace of the VolumeGroupSnapshot
+	vgsc, conflict, err := r.createVolumeGroupSnapshotContent(ctx, cvgsc, vgs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if conflict != "" {
+		return r.reportNotBound(ctx, cvgsc, originalStatus, volumegroupv1alpha1.ReasonContentConflict, conflict)
+	}
+	cvgsc.Status.VolumeGroupSnapshotContentName = &vgsc.Name
+
+	// VolumeSnapshots are controlled by the VolumeGroupSnapshotContent, so they are created after it,
+	// and listed in it only once all of them exist
+	vsNames, conflict, err := r.createVolumeSnapshots(ctx, cvgsc, vgsc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if conflict != "" {
+		return r.reportNotBound(ctx, cvgsc, originalStatus, volumegroupv1alpha1.ReasonSnapshotConflict, conflict)
+	}
+
+	if len(vgsc.Spec.SnapshotList) == 0 {
+		patch := client.MergeFromWithOptions(vgsc.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		vgsc.Spec.SnapshotList = vsNames
+		if err := r.Patch(ctx, vgsc, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionSnapshotBound, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonBound, fmt.Sprintf("Bound to VolumeGroupSnapshot %s/%s", vgs.Namespace, vgs.Name))
+
+	if vgs.Spec.BoundVolumeGroupSnapshotContentName == nil {
+		vgs.Spec.BoundVolumeGroupSnapshotContentName = &vgsc.Name
+		if err := r.Update(ctx, vgs); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	readyToUse := vgsc.Status.ReadyToUse != nil && *vgsc.Status.ReadyToUse
+	if failed := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed); failed != nil && failed.Status == metav1.ConditionTrue {
+		message := fmt.Sprintf("VolumeGroupSnapshotContent %s/%s has failed: %s", vgsc.Namespace, vgsc.Name, failed.Message)
+		cvgsc.Status.Error = vgsc.Status.Error
+		if cvgsc.Status.Error == nil {
+			cvgsc.Status.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{Time: &failed.LastTransitionTime, Message: &failed.Message}
+		}
+		setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+			failed.Reason, message)
+		setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			failed.Reason, message)
+	} else if readyToUse {
+		cvgsc.Status.ReadyToUse = &readyToUse
+		setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonReady, fmt.Sprintf("VolumeGroupSnapshotContent %s/%s is ready to use", vgsc.Namespace, vgsc.Name))
+	} else {
+		setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForContent, fmt.Sprintf("VolumeGroupSnapshotContent %s/%s is not ready to use", vgsc.Namespace, vgsc.Name))
+	}
+
+	if err := r.updateStatus(ctx, cvgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Progress of the VolumeGroupSnapshotContent is watched
+	return ctrl.Result{}, nil
+}
+
+// reportNotBound records why the binding can't complete, which is checked again when the watched objects are fixed
+func (r *ClusterVolumeGroupSnapshotContentReconciler) reportNotBound(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, originalStatus *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentStatus, reason, message string) (ctrl.Result, error) {
+	setCondition(&cvgsc.Status.Conditions, cvgsc.Generation, volumegroupv1alpha1.ConditionSnapshotBound, metav1.ConditionFalse,
+		reason, message)
+	if err := r.updateStatus(ctx, cvgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// snapshotBindingError returns the reason why the ClusterVolumeGroupSnapshotContent can't be bound to the VolumeGroupSnapshot,
+// or an empty string if it refers back to the ClusterVolumeGroupSnapshotContent
+func snapshotBindingError(cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) string {
+	if vgs.Spec.ClusterVolumeGroupSnapshotContentName == nil || *vgs.Spec.ClusterVolumeGroupSnapshotContentName != cvgsc.Name {
+		return fmt.Sprintf("VolumeGroupSnapshot %s/%s does not refer to ClusterVolumeGroupSnapshotContent %s", vgs.Namespace, vgs.Name, cvgsc.Name)
+	}
+
+	if uid := cvgsc.Spec.VolumeGroupSnapshotRef.UID; uid != "" && uid != vgs.UID {
+		return fmt.Sprintf("ClusterVolumeGroupSnapshotContent %s is bound to another VolumeGroupSnapshot with UID %s", cvgsc.Name, uid)
+	}
+
+	return ""
+}
+
+// volumeSnapshotNameFor returns the name of the VolumeSnapshot pre-bound to the VolumeSnapshotContent
+func volumeSnapshotNameFor(cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, vscName string) string {
+	return generatedName("vs", cvgsc.Name, vscName)
+}
+
+// volumeGroupSnapshotContentNameFor returns the name of the VolumeGroupSnapshotContent created for the VolumeGroupSnapshot
+func volumeGroupSnapshotContentNameFor(vgs *volumegroupv1alpha1.VolumeGroupSnapshot) string {
+	return generatedName("vgsc", vgs.Name)
+}
+
+// validateVolumeSnapshotContents verifies that the VolumeSnapshotContents are pre-bound to the VolumeSnapshots to be
+// created in the namespace of the VolumeGroupSnapshot, and that they are retained when the VolumeSnapshots are deleted.
+// VolumeSnapshotRef of a VolumeSnapshotContent is immutable, so it needs to be set by the administrator.
+// It returns the reason and the message if a VolumeSnapshotContent can't be bound.
+func (r *ClusterVolumeGroupSnapshotContentReconciler) validateVolumeSnapshotContents(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (string, string, error) {
+	for _, vscName := range cvgsc.Spec.VolumeSnapshotContentNames {
+		vsc := &snapshotv1.VolumeSnapshotContent{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vscName}, vsc); err != nil {
+			if errors.IsNotFound(err) {
+				return volumegroupv1alpha1.ReasonContentNotFound, fmt.Sprintf("VolumeSnapshotContent %s is not found", vscName), nil
+			}
+			return "", "", err
+		}
+
+		vsRef := vsc.Spec.VolumeSnapshotRef
+		vsName := volumeSnapshotNameFor(cvgsc, vscName)
+		if vsRef.Namespace != vgs.Namespace || vsRef.Name != vsName {
+			return volumegroupv1alpha1.ReasonBindingMismatch,
+				fmt.Sprintf("VolumeSnapshotContent %s needs to refer to VolumeSnapshot %s/%s, but refers to %s/%s", vsc.Name, vgs.Namespace, vsName, vsRef.Namespace, vsRef.Name), nil
+		}
+
+		if vsRef.UID != "" {
+			// Bound to a VolumeSnapshot once, which needs to be the one created for this group
+			vs := &snapshotv1.VolumeSnapshot{}
+			if err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgs.Namespace}, vs); err != nil && !errors.IsNotFound(err) {
+				return "", "", err
+			} else if err != nil || vs.UID != vsRef.UID {
+				return volumegroupv1alpha1.ReasonBindingMismatch,
+					fmt.Sprintf("VolumeSnapshotContent %s is bound to another VolumeSnapshot with UID %s", vsc.Name, vsRef.UID), nil
+			}
+		}
+
+		if vsc.Spec.DeletionPolicy != snapshotv1.VolumeSnapshotContentRetain {
+			return volumegroupv1alpha1.ReasonNotRetained,
+				fmt.Sprintf("VolumeSnapshotContent %s needs deletionPolicy %s, but has %s", vsc.Name, snapshotv1.VolumeSnapshotContentRetain, vsc.Spec.DeletionPolicy), nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// createVolumeGroupSnapshotContent creates the VolumeGroupSnapshotContent in the namespace of the VolumeGroupSnapshot.
+// It returns the reason if a VolumeGroupSnapshotContent with the same name exists but wasn't created for the binding.
+func (r *ClusterVolumeGroupSnapshotContentReconciler) createVolumeGroupSnapshotContent(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (*volumegroupv1alpha1.VolumeGroupSnapshotContent, string, error) {
+	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      volumeGroupSnapshotContentNameFor(vgs),
+			Namespace: vgs.Namespace,
+			Labels: map[string]string{
+				volumegroupv1alpha1.VolumeGroupSnapshotLabel:               vgs.Name,
+				volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentLabel: cvgsc.Name,
+			},
+		},
+		Spec: volumegroupv1alpha1.VolumeGroupSnapshotContentSpec{
+			VolumeGroupSnapshotName:   &vgs.Name,
+			VolumeGroupSnapshotUID:    vgs.UID,
+			PersistentVolumeClaimList: []string{},
+			SnapshotList:              []string{},
+			Timeout:                   vgs.Spec.Timeout,
+			// The group contents are retained by the ClusterVolumeGroupSnapshotContent
+			FailurePolicy:  volumegroupv1alpha1.FailurePolicyRetain,
+			DeletionPolicy: volumegroupv1alpha1.DeletionPolicyRetain,
+		},
+	}
+	if err := r.Create(ctx, vgsc); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return nil, "", err
+		}
+
+		// Only adopt the VolumeGroupSnapshotContent created for this binding
+		existing := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vgsc.Name, Namespace: vgsc.Namespace}, existing); err != nil {
+			return nil, "", err
+		}
+		if existing.Labels[volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentLabel] != cvgsc.Name ||
+			existing.Labels[volumegroupv1alpha1.VolumeGroupSnapshotLabel] != vgs.Name || existing.Spec.VolumeGroupSnapshotUID != vgs.UID {
+			return nil, fmt.Sprintf("VolumeGroupSnapshotContent %s/%s already exists and was not created for ClusterVolumeGroupSnapshotContent %s", existing.Namespace, existing.Name, cvgsc.Name), nil
+		}
+		vgsc = existing
+	}
+
+	return vgsc, "", nil
+}
+
+// createVolumeSnapshots creates the VolumeSnapshots pre-bound to the VolumeSnapshotContents in the namespace of the
+// VolumeGroupSnapshotContent, and returns their names. It returns the reason if an existing VolumeSnapshot wasn't
+// created for the group.
+func (r *ClusterVolumeGroupSnapshotContentReconciler) createVolumeSnapshots(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) ([]string, string, error) {
+	vsNames := make([]string, 0, len(cvgsc.Spec.VolumeSnapshotContentNames))
+	for _, vscName := range cvgsc.Spec.VolumeSnapshotContentNames {
+		vsc := &snapshotv1.VolumeSnapshotContent{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vscName}, vsc); err != nil {
+			return nil, "", err
+		}
+
+		contentName := vscName
+		vs := &snapshotv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      volumeSnapshotNameFor(cvgsc, vscName),
+				Namespace: vgsc.Namespace,
+				Labels: map[string]string{
+					volumegroupv1alpha1.VolumeGroupSnapshotContentLabel: vgsc.Name,
+				},
+			},
+			Spec: snapshotv1.VolumeSnapshotSpec{
+				Source: snapshotv1.VolumeSnapshotSource{
+					VolumeSnapshotContentName: &contentName,
+				},
+				VolumeSnapshotClassName: vsc.Spec.VolumeSnapshotClassName,
+			},
+		}
+
+		if err := ctrl.SetControllerReference(vgsc, vs, r.Scheme); err != nil {
+			return nil, "", err
+		}
+
+		if err := r.Create(ctx, vs); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return nil, "", err
+			}
+
+			// Only adopt the VolumeSnapshot created for this group
+			existing := &snapshotv1.VolumeSnapshot{}
+			if err := r.Get(ctx, types.NamespacedName{Name: vs.Name, Namespace: vs.Namespace}, existing); err != nil {
+				return nil, "", err
+			}
+			if conflict := volumeSnapshotConflict(vgsc, "VolumeGroupSnapshotContent", existing, vs.Spec.Source); conflict != "" {
+				return nil, conflict, nil
+			}
+		}
+		vsNames = append(vsNames, vs.Name)
+	}
+
+	return vsNames, "", nil
+}
+
+// finalize deletes the VolumeSnapshotContents according to the DeletionPolicy, then removes the finalizer
+func (r *ClusterVolumeGroupSnapshotContentReconciler) finalize(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent) error {
+	if !controllerutil.ContainsFinalizer(cvgsc, clusterVolumeGroupSnapshotContentFinalizer) {
+		return nil
+	}
+
+	if cvgsc.Spec.DeletionPolicy == volumegroupv1alpha1.DeletionPolicyDelete {
+		for _, vscName := range cvgsc.Spec.VolumeSnapshotContentNames {
+			vsc := &snapshotv1.VolumeSnapshotContent{ObjectMeta: metav1.ObjectMeta{Name: vscName}}
+			if err := r.Delete(ctx, vsc); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(cvgsc, clusterVolumeGroupSnapshotContentFinalizer)
+	return r.Update(ctx, cvgsc)
+}
+
+// updateStatus updates the status of the ClusterVolumeGroupSnapshotContent only if it differs from the original
+func (r *ClusterVolumeGroupSnapshotContentReconciler) updateStatus(ctx context.Context, cvgsc *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent, original *volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentStatus) error {
+	cvgsc.Status.ObservedGeneration = cvgsc.Generation
+
+	if equality.Semantic.DeepEqual(original, &cvgsc.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, cvgsc)
+}
+
+// clusterVolumeGroupSnapshotContentsForSnapshot maps a VolumeGroupSnapshot to the ClusterVolumeGroupSnapshotContent it refers to
+func (r *ClusterVolumeGroupSnapshotContentReconciler) clusterVolumeGroupSnapshotContentsForSnapshot(obj client.Object) []reconcile.Request {
+	vgs, ok := obj.(*volumegroupv1alpha1.VolumeGroupSnapshot)
+	if !ok || vgs.Spec.ClusterVolumeGroupSnapshotContentName == nil {
+		return []reconcile.Request{}
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: *vgs.Spec.ClusterVolumeGroupSnapshotContentName}},
+	}
+}
+
+// clusterVolumeGroupSnapshotContentsForContent maps a VolumeGroupSnapshotContent to the ClusterVolumeGroupSnapshotContent
+// referred to by its VolumeGroupSnapshot
+func (r *ClusterVolumeGroupSnapshotContentReconciler) clusterVolumeGroupSnapshotContentsForContent(obj client.Object) []reconcile.Request {
+	vgsc, ok := obj.(*volumegroupv1alpha1.VolumeGroupSnapshotContent)
+	if !ok || vgsc.Spec.VolumeGroupSnapshotName == nil {
+		return []reconcile.Request{}
+	}
+
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: *vgsc.Spec.VolumeGroupSnapshotName, Namespace: vgsc.Namespace}, vgs); err != nil {
+		return []reconcile.Request{}
+	}
+
+	return r.clusterVolumeGroupSnapshotContentsForSnapshot(vgs)
+}
+
+// clusterVolumeGroupSnapshotContentsForVolumeSnapshotContent maps a VolumeSnapshotContent to the
+// ClusterVolumeGroupSnapshotContents listing it
+func (r *ClusterVolumeGroupSnapshotContentReconciler) clusterVolumeGroupSnapshotContentsForVolumeSnapshotContent(obj client.Object) []reconcile.Request {
+	cvgscList := &volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentList{}
+	if err := r.List(context.Background(), cvgscList, client.MatchingFields{volumeSnapshotContentNamesIndex: obj.GetName()}); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, cvgsc := range cvgscList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: cvgsc.Name},
+		})
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterVolumeGroupSnapshotContentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent{}).
+		Watches(&source.Kind{Type: &volumegroupv1alpha1.VolumeGroupSnapshot{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterVolumeGroupSnapshotContentsForSnapshot)).
+		Watches(&source.Kind{Type: &volumegroupv1alpha1.VolumeGroupSnapshotContent{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterVolumeGroupSnapshotContentsForContent)).
+		Watches(&source.Kind{Type: &snapshotv1.VolumeSnapshotContent{}},
+			handler.EnqueueRequestsFromMapFunc(r.clusterVolumeGroupSnapshotContentsForVolumeSnapshotContent)).
+		Complete(r)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestClusterContent returns a ClusterVolumeGroupSnapshotContent of the pre-provisioned VolumeSnapshotContents
+// referred to by a VolumeGroupSnapshot, with the VolumeSnapshotContents pre-bound to the VolumeSnapshots to be created
+func newTestClusterContent(vscNames ...string) []client.Object {
+	cvgscName := "cvgsc"
+	cvgsc := &volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       cvgscName,
+			UID:        "cvgsc-uid",
+			Generation: 1,
+			Finalizers: []string{clusterVolumeGroupSnapshotContentFinalizer},
+		},
+		Spec: volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentSpec{
+			VolumeGroupSnapshotRef:     corev1.ObjectReference{Namespace: "ns", Name: "vgs"},
+			VolumeSnapshotContentNames: vscNames,
+		},
+	}
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "vgs", Namespace: "ns", UID: "vgs-uid"},
+		Spec:       volumegroupv1alpha1.VolumeGroupSnapshotSpec{ClusterVolumeGroupSnapshotContentName: &cvgscName},
+	}
+
+	objs := []client.Object{cvgsc, vgs}
+	for _, vscName := range vscNames {
+		objs = append(objs, &snapshotv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: vscName},
+			Spec: snapshotv1.VolumeSnapshotContentSpec{
+				VolumeSnapshotRef: corev1.ObjectReference{Namespace: "ns", Name: volumeSnapshotNameFor(cvgsc, vscName)},
+				DeletionPolicy:    snapshotv1.VolumeSnapshotContentRetain,
+			},
+		})
+	}
+
+	return objs
+}
+
+// snapshotListClient verifies that a VolumeGroupSnapshotContent is created with an empty SnapshotList,
+// and that the VolumeSnapshots exist when they are added to it
+type snapshotListClient struct {
+	client.Client
+	t *testing.T
+}
+
+func (c *snapshotListClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if vgsc, ok := obj.(*volumegroupv1alpha1.VolumeGroupSnapshotContent); ok && len(vgsc.Spec.SnapshotList) != 0 {
+		c.t.Errorf("expected VolumeGroupSnapshotContent to be created before its VolumeSnapshots, got %v", vgsc.Spec.SnapshotList)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *snapshotListClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if vgsc, ok := obj.(*volumegroupv1alpha1.VolumeGroupSnapshotContent); ok {
+		for _, vsName := range vgsc.Spec.SnapshotList {
+			if err := c.Client.Get(ctx, client.ObjectKey{Name: vsName, Namespace: vgsc.Namespace}, &snapshotv1.VolumeSnapshot{}); err != nil {
+				c.t.Errorf("expected VolumeSnapshot %s to exist before it is listed, got %v", vsName, err)
+			}
+		}
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestReconcileClusterContentListsCreatedSnapshots(t *testing.T) {
+	objs := newTestClusterContent("vsc-1", "vsc-2")
+	cvgsc := objs[0].(*volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent)
+	c := newFakeClient(t, objs...)
+	r := &ClusterVolumeGroupSnapshotContentReconciler{Client: &snapshotListClient{Client: c, t: t}, Scheme: c.Scheme()}
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cvgsc)}); err != nil {
+		t.Fatal(err)
+	}
+
+	getObject(t, c, cvgsc)
+	if cvgsc.Status.VolumeGroupSnapshotContentName == nil {
+		t.Fatal("expected VolumeGroupSnapshotContent to be created")
+	}
+	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+	if err := c.Get(ctx, client.ObjectKey{Name: *cvgsc.Status.VolumeGroupSnapshotContentName, Namespace: "ns"}, vgsc); err != nil {
+		t.Fatal(err)
+	}
+	if len(vgsc.Spec.SnapshotList) != 2 {
+		t.Fatalf("expected both VolumeSnapshots to be listed, got %v", vgsc.Spec.SnapshotList)
+	}
+	for _, vsName := range vgsc.Spec.SnapshotList {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := c.Get(ctx, client.ObjectKey{Name: vsName, Namespace: "ns"}, vs); err != nil {
+			t.Fatal(err)
+		}
+		if !metav1.IsControlledBy(vs, vgsc) {
+			t.Fatalf("expected VolumeSnapshot %s to be controlled by the VolumeGroupSnapshotContent", vsName)
+		}
+	}
+}
+
+func TestReconcileContentWaitsForClusterContent(t *testing.T) {
+	vgsc := newTestContent()
+	vgsc.Labels = map[string]string{volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentLabel: "cvgsc"}
+
+	c := newFakeClient(t, vgsc)
+	r := &VolumeGroupSnapshotContentReconciler{Client: c}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgsc)}); err != nil {
+		t.Fatal(err)
+	}
+
+	getObject(t, c, vgsc)
+	created := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionSnapshotsCreated)
+	if created == nil || created.Status != metav1.ConditionFalse || created.Reason != volumegroupv1alpha1.ReasonWaitingForContent {
+		t.Fatalf("expected to wait for the VolumeSnapshots, got %+v", created)
+	}
+	if vgsc.Status.ReadyToUse != nil && *vgsc.Status.ReadyToUse {
+		t.Fatal("expected the content without VolumeSnapshots not to be ready to use")
+	}
+}
+
+func TestReconcileClusterContentCopiesFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		error bool
+	}{
+		{name: "with error", error: true},
+		{name: "condition only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := newTestClusterContent("vsc-1")
+			cvgsc := objs[0].(*volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent)
+			vgs := objs[1].(*volumegroupv1alpha1.VolumeGroupSnapshot)
+
+			// The VolumeGroupSnapshotContent created for the binding has failed
+			vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      volumeGroupSnapshotContentNameFor(vgs),
+					Namespace: "ns",
+					UID:       "vgsc-uid",
+					Labels: map[string]string{
+						volumegroupv1alpha1.VolumeGroupSnapshotLabel:               vgs.Name,
+						volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentLabel: cvgsc.Name,
+					},
+				},
+				Spec: volumegroupv1alpha1.VolumeGroupSnapshotContentSpec{
+					VolumeGroupSnapshotUID: vgs.UID,
+					SnapshotList:           []string{volumeSnapshotNameFor(cvgsc, "vsc-1")},
+				},
+			}
+			setContentFailed(vgsc, volumegroupv1alpha1.ReasonSnapshotNotFound, "VolumeSnapshot is not found")
+			if !tt.error {
+				vgsc.Status.Error = nil
+			}
+			vscName := "vsc-1"
+			vs := newTestVolumeSnapshot(volumeSnapshotNameFor(cvgsc, vscName), "", vgsc)
+			vs.Spec.Source = snapshotv1.VolumeSnapshotSource{VolumeSnapshotContentName: &vscName}
+
+			c := newFakeClient(t, append(objs, vgsc, vs)...)
+			r := &ClusterVolumeGroupSnapshotContentReconciler{Client: c, Scheme: c.Scheme()}
+			req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cvgsc)}
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+
+			getObject(t, c, cvgsc)
+			failed := meta.FindStatusCondition(cvgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed)
+			if failed == nil || failed.Status != metav1.ConditionTrue || failed.Reason != volumegroupv1alpha1.ReasonSnapshotNotFound {
+				t.Fatalf("expected the failure to be copied, got %+v", failed)
+			}
+			if cvgsc.Status.Error == nil || cvgsc.Status.Error.Message == nil {
+				t.Fatal("expected the error to be copied")
+			}
+			if tt.error && *cvgsc.Status.Error.Message != *vgsc.Status.Error.Message {
+				t.Fatalf("expected the error of the VolumeGroupSnapshotContent, got %s", *cvgsc.Status.Error.Message)
+			}
+			if cvgsc.Status.ReadyToUse != nil && *cvgsc.Status.ReadyToUse {
+				t.Fatal("expected the failed content not to be ready to use")
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestScheme returns the scheme with all the types the reconcilers handle
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		volumegroupv1alpha1.AddToScheme,
+		snapshotv1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return scheme
+}
+
+// newFakeClient returns a fake client holding the objects, which lists them by the field indexes as the cache does
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	return &indexedClient{Client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build()}
+}
+
+// indexedClient filters the listed objects by the field selectors with fieldIndexes, which the fake client ignores
+type indexedClient struct {
+	client.Client
+}
+
+func (c *indexedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector == nil || listOpts.FieldSelector.Empty() {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	filtered := []runtime.Object{}
+	for _, item := range items {
+		matched, err := matchesFieldIndexes(item.(client.Object), listOpts.FieldSelector.Requirements())
+		if err != nil {
+			return err
+		}
+		if matched {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return meta.SetList(list, filtered)
+}
+
+// matchesFieldIndexes returns whether the indexed values of the object satisfy all the requirements
+func matchesFieldIndexes(obj client.Object, requirements fields.Requirements) (bool, error) {
+	for _, requirement := range requirements {
+		var index *fieldIndex
+		for i := range fieldIndexes {
+			if fieldIndexes[i].field == requirement.Field && reflect.TypeOf(fieldIndexes[i].obj) == reflect.TypeOf(obj) {
+				index = &fieldIndexes[i]
+			}
+		}
+		if index == nil {
+			return false, fmt.Errorf("no field index %s for %T", requirement.Field, obj)
+		}
+
+		matched := false
+		for _, value := range index.extract(obj) {
+			if value == requirement.Value {
+				matched = true
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// getObject reads the object back from the client, failing the test if it isn't found
+func getObject(t *testing.T, c client.Client, obj client.Object) {
+	t.Helper()
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingStatusClient fails the updates of the status with the error
+type failingStatusClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingStatusClient) Status() client.StatusWriter {
+	return &failingStatusWriter{StatusWriter: c.Client.Status(), err: c.err}
+}
+
+type failingStatusWriter struct {
+	client.StatusWriter
+	err error
+}
+
+func (w *failingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return w.err
+}
+
+func (w *failingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.err
+}
+
+// concurrencyClient records the maximum number of the creations in progress at the same time.
+// Each creation is delayed so that the concurrent ones overlap.
+type concurrencyClient struct {
+	client.Client
+	delay time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+	created  int
+}
+
+func (c *concurrencyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.max {
+		c.max = c.inFlight
+	}
+	c.mu.Unlock()
+
+	time.Sleep(c.delay)
+	err := c.Client.Create(ctx, obj, opts...)
+
+	c.mu.Lock()
+	c.inFlight--
+	if err == nil {
+		c.created++
+	}
+	c.mu.Unlock()
+	return err
+}
@@ -0,0 +1,553 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// hookConditions maps the hooks to the conditions reporting their results
+var hookConditions = map[volumegroupv1alpha1.HookName]string{
+	volumegroupv1alpha1.HookPreSnapshot:  volumegroupv1alpha1.ConditionPreSnapshotHookSucceeded,
+	volumegroupv1alpha1.HookPostSnapshot: volumegroupv1alpha1.ConditionPostSnapshotHookSucceeded,
+
+	volumegroupv1alpha1.HookPreSnapshotHTTP:  volumegroupv1alpha1.ConditionPreSnapshotHTTPHookSucceeded,
+	volumegroupv1alpha1.HookPostSnapshotHTTP: volumegroupv1alpha1.ConditionPostSnapshotHTTPHookSucceeded,
+}
+
+// hookStatusFor returns the status of the hook, or nil if the hook hasn't started
+func hookStatusFor(statuses []volumegroupv1alpha1.HookStatus, name volumegroupv1alpha1.HookName) *volumegroupv1alpha1.HookStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// hookCompleted returns whether the hook has succeeded or failed
+func hookCompleted(statuses []volumegroupv1alpha1.HookStatus, name volumegroupv1alpha1.HookName) bool {
+	status := hookStatusFor(statuses, name)
+	return status != nil && status.Phase != volumegroupv1alpha1.HookPhaseRunning
+}
+
+// postSnapshotHooksCompleted returns whether all the post-snapshot hooks have succeeded or failed.
+// It is true if no post-snapshot hook is defined.
+func postSnapshotHooksCompleted(hooks *volumegroupv1alpha1.SnapshotHooks, statuses []volumegroupv1alpha1.HookStatus) bool {
+	if hooks == nil {
+		return true
+	}
+
+	if hooks.PostSnapshotHTTP != nil && !hookCompleted(statuses, volumegroupv1alpha1.HookPostSnapshotHTTP) {
+		return false
+	}
+
+	return hooks.PostSnapshot == nil || hookCompleted(statuses, volumegroupv1alpha1.HookPostSnapshot)
+}
+
+// defaultPostSnapshotHookTimeout bounds the post-snapshot Job if the hooks don't have a timeout.
+// The Job runs after the group snapshot completes, fails or is deleted, so the timeout of the group snapshot doesn't bound it.
+const defaultPostSnapshotHookTimeout = 10 * time.Minute
+
+// hookTimeout returns the duration from the start of the hook within which the hook needs to complete, if any
+func hookTimeout(hooks *volumegroupv1alpha1.SnapshotHooks, name volumegroupv1alpha1.HookName) (time.Duration, bool) {
+	if hooks != nil && hooks.Timeout != nil && hooks.Timeout.Duration > 0 {
+		return hooks.Timeout.Duration, true
+	}
+	if name == volumegroupv1alpha1.HookPostSnapshot {
+		return defaultPostSnapshotHookTimeout, true
+	}
+	return 0, false
+}
+
+// hookDeadline returns the time by which the hook needs to complete, if the hook has a timeout
+func hookDeadline(hooks *volumegroupv1alpha1.SnapshotHooks, status *volumegroupv1alpha1.HookStatus) (time.Time, bool) {
+	if status == nil || status.StartTime == nil {
+		return time.Time{}, false
+	}
+
+	timeout, ok := hookTimeout(hooks, status.Name)
+	if !ok {
+		return time.Time{}, false
+	}
+	return status.StartTime.Add(timeout), true
+}
+
+// requeueForHook returns the result to reconcile again when the running hook is retried or times out,
+// or earlier if the given result does so
+func requeueForHook(result ctrl.Result, hooks *volumegroupv1alpha1.SnapshotHooks, status *volumegroupv1alpha1.HookStatus) ctrl.Result {
+	if status == nil || status.Phase != volumegroupv1alpha1.HookPhaseRunning {
+		return result
+	}
+
+	if deadline, ok := hookDeadline(hooks, status); ok {
+		result = requeueNoLaterThan(result, deadline)
+	}
+
+	if status.LastAttemptTime != nil {
+		result = requeueNoLaterThan(result, status.LastAttemptTime.Add(httpHookBackoff(status.Attempts)))
+	}
+
+	return result
+}
+
+// requeueNoLaterThan returns the result to reconcile again at the time, or earlier if the given result does so
+func requeueNoLaterThan(result ctrl.Result, t time.Time) ctrl.Result {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctrl.Result{Requeue: true}
+	}
+	if result.Requeue || (result.RequeueAfter > 0 && result.RequeueAfter < d) {
+		return result
+	}
+	return ctrl.Result{RequeueAfter: d}
+}
+
+// hookJobName returns the name of the Job run for the hook of the VolumeGroupSnapshotContent
+func hookJobName(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, name volumegroupv1alpha1.HookName) string {
+	return fmt.Sprintf("%s-%s", vgsc.Name, strings.ToLower(string(name)))
+}
+
+// hookJobFor returns the Job for the hook created from its template
+func (r *VolumeGroupSnapshotContentReconciler) hookJobFor(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, name volumegroupv1alpha1.HookName, template *batchv1.JobTemplateSpec) (*batchv1.Job, error) {
+	labels := map[string]string{}
+	for k, v := range template.Labels {
+		labels[k] = v
+	}
+	labels[volumegroupv1alpha1.VolumeGroupSnapshotContentLabel] = vgsc.Name
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        hookJobName(vgsc, name),
+			Namespace:   vgsc.Namespace,
+			Labels:      labels,
+			Annotations: template.Annotations,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+
+	if err := ctrl.SetControllerReference(vgsc, job, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// setHookStatus records the status of the hook and the condition reporting it
+func setHookStatus(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, status volumegroupv1alpha1.HookStatus, reason, message string) {
+	if current := hookStatusFor(vgsc.Status.Hooks, status.Name); current != nil {
+		*current = status
+	} else {
+		vgsc.Status.Hooks = append(vgsc.Status.Hooks, status)
+	}
+
+	conditionStatus := metav1.ConditionFalse
+	if status.Phase == volumegroupv1alpha1.HookPhaseSucceeded {
+		conditionStatus = metav1.ConditionTrue
+	}
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, hookConditions[status.Name], conditionStatus, reason, message)
+}
+
+// jobCondition returns the condition of the Job if it is true
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == conditionType && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// runHook creates the Job for the hook if not yet, and records the status of the hook from the Job.
+// It returns the status of the hook, or nil if the hook isn't defined.
+func (r *VolumeGroupSnapshotContentReconciler) runHook(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, name volumegroupv1alpha1.HookName, template *batchv1.JobTemplateSpec) (*volumegroupv1alpha1.HookStatus, error) {
+	if template == nil {
+		return nil, nil
+	}
+
+	status := hookStatusFor(vgsc.Status.Hooks, name)
+	if status != nil && status.Phase != volumegroupv1alpha1.HookPhaseRunning {
+		// Hooks are run only once
+		return status, nil
+	}
+
+	jobName := hookJobName(vgsc, name)
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: vgsc.Namespace}, job); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		if status == nil {
+			// Templates are checked before the Job is created on behalf of the user
+			message, err := r.hookTemplateError(ctx, vgsc, template)
+			if err != nil {
+				return nil, err
+			}
+			if message != "" {
+				now := metav1.Now()
+				message = fmt.Sprintf("Job %s is not allowed: %s", jobName, message)
+				setHookStatus(vgsc, volumegroupv1alpha1.HookStatus{
+					Name:           name,
+					Phase:          volumegroupv1alpha1.HookPhaseFailed,
+					StartTime:      &now,
+					CompletionTime: &now,
+					Message:        message,
+				}, volumegroupv1alpha1.ReasonHookFailed, message)
+				return hookStatusFor(vgsc.Status.Hooks, name), nil
+			}
+		}
+
+		job, err = r.hookJobFor(vgsc, name, template)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+			if !errors.IsForbidden(err) && !errors.HasStatusCause(err, corev1.NamespaceTerminatingCause) {
+				return nil, err
+			}
+
+			// Jobs can't be created in the terminating namespace, or aren't allowed by the quota or the admission,
+			// so the hook fails instead of blocking the group snapshot and its deletion
+			now := metav1.Now()
+			message := fmt.Sprintf("Job %s can't be created: %v", jobName, err)
+			setHookStatus(vgsc, volumegroupv1alpha1.HookStatus{
+				Name:           name,
+				JobName:        jobName,
+				Phase:          volumegroupv1alpha1.HookPhaseFailed,
+				StartTime:      &now,
+				CompletionTime: &now,
+				Message:        message,
+			}, volumegroupv1alpha1.ReasonHookFailed, message)
+			return hookStatusFor(vgsc.Status.Hooks, name), nil
+		}
+
+		if status == nil {
+			now := metav1.Now()
+			setHookStatus(vgsc, volumegroupv1alpha1.HookStatus{
+				Name:      name,
+				JobName:   jobName,
+				Phase:     volumegroupv1alpha1.HookPhaseRunning,
+				StartTime: &now,
+			}, volumegroupv1alpha1.ReasonHookRunning, fmt.Sprintf("Job %s is running", jobName))
+		}
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	newStatus := volumegroupv1alpha1.HookStatus{
+		Name:      name,
+		JobName:   jobName,
+		Phase:     volumegroupv1alpha1.HookPhaseRunning,
+		StartTime: job.CreationTimestamp.DeepCopy(),
+	}
+	if status != nil && status.StartTime != nil {
+		newStatus.StartTime = status.StartTime
+	}
+
+	if !metav1.IsControlledBy(job, vgsc) {
+		// Jobs not created for this VolumeGroupSnapshotContent aren't adopted
+		now := metav1.Now()
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseFailed
+		newStatus.CompletionTime = &now
+		newStatus.Message = fmt.Sprintf("Job %s exists but is not owned by VolumeGroupSnapshotContent %s", jobName, vgsc.Name)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookFailed, newStatus.Message)
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	if jobCondition(job, batchv1.JobComplete) != nil {
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseSucceeded
+		newStatus.CompletionTime = job.Status.CompletionTime.DeepCopy()
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookSucceeded, fmt.Sprintf("Job %s succeeded", jobName))
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	if failed := jobCondition(job, batchv1.JobFailed); failed != nil {
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseFailed
+		newStatus.CompletionTime = failed.LastTransitionTime.DeepCopy()
+		newStatus.Message = fmt.Sprintf("Job %s failed: %s", jobName, failed.Message)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookFailed, newStatus.Message)
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	if deadline, ok := hookDeadline(vgsc.Spec.Hooks, &newStatus); ok && !time.Now().Before(deadline) {
+		// Stop the Job, so that it doesn't act after the hook is given up. The Job is kept until its Pods are gone.
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		now := metav1.Now()
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseFailed
+		newStatus.CompletionTime = &now
+		timeout, _ := hookTimeout(vgsc.Spec.Hooks, name)
+		newStatus.Message = fmt.Sprintf("Job %s did not complete within %s", jobName, timeout)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookTimeout, newStatus.Message)
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookRunning, fmt.Sprintf("Job %s is running", jobName))
+	return hookStatusFor(vgsc.Status.Hooks, name), nil
+}
+
+// hookTemplateError returns the reason why the Job template can't be run for the VolumeGroupSnapshotContent,
+// or an empty string if it is allowed
+func (r *VolumeGroupSnapshotContentReconciler) hookTemplateError(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, template *batchv1.JobTemplateSpec) (string, error) {
+	podSpec := &template.Spec.Template.Spec
+	if podSpec.HostNetwork || podSpec.HostPID || podSpec.HostIPC {
+		return "host namespaces are not allowed", nil
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			return fmt.Sprintf("hostPath volume %s is not allowed", volume.Name), nil
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		if sc := container.SecurityContext; sc != nil {
+			if (sc.Privileged != nil && *sc.Privileged) || (sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation) ||
+				(sc.Capabilities != nil && len(sc.Capabilities.Add) > 0) {
+				return fmt.Sprintf("privileged container %s is not allowed", container.Name), nil
+			}
+		}
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				return fmt.Sprintf("host port %d of container %s is not allowed", port.HostPort, container.Name), nil
+			}
+		}
+	}
+
+	saName := podSpec.ServiceAccountName
+	if saName == "" {
+		saName = podSpec.DeprecatedServiceAccount
+	}
+	if podSpec.DeprecatedServiceAccount != "" && podSpec.DeprecatedServiceAccount != saName {
+		return fmt.Sprintf("serviceAccount %s differs from serviceAccountName %s", podSpec.DeprecatedServiceAccount, saName), nil
+	}
+	if saName == "" {
+		saName = "default"
+	}
+
+	sa := &corev1.ServiceAccount{}
+	if err := r.Get(ctx, types.NamespacedName{Name: saName, Namespace: vgsc.Namespace}, sa); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("ServiceAccount %s is not found", saName), nil
+		}
+		return "", err
+	}
+	if sa.Annotations[volumegroupv1alpha1.HookServiceAccountAnnotation] != "true" {
+		return fmt.Sprintf("ServiceAccount %s is not annotated with %s=true", saName, volumegroupv1alpha1.HookServiceAccountAnnotation), nil
+	}
+
+	return "", nil
+}
+
+// stopPreSnapshotHook stops the pre-snapshot Job if it is still running, so that the post-snapshot hooks don't run
+// while or before it quiesces the applications. It returns true once the Job has completed, failed or is gone.
+func (r *VolumeGroupSnapshotContentReconciler) stopPreSnapshotHook(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (bool, error) {
+	if vgsc.Spec.Hooks == nil || vgsc.Spec.Hooks.PreSnapshot == nil {
+		return true, nil
+	}
+
+	// The Job may be created without its status being recorded, so it is always looked up
+	jobName := hookJobName(vgsc, volumegroupv1alpha1.HookPreSnapshot)
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: vgsc.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !metav1.IsControlledBy(job, vgsc) || jobCondition(job, batchv1.JobComplete) != nil || jobCondition(job, batchv1.JobFailed) != nil {
+		return true, nil
+	}
+
+	if job.DeletionTimestamp.IsZero() {
+		// The Job is kept until its Pods are gone
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	if status := hookStatusFor(vgsc.Status.Hooks, volumegroupv1alpha1.HookPreSnapshot); status == nil || status.Phase == volumegroupv1alpha1.HookPhaseRunning {
+		now := metav1.Now()
+		newStatus := volumegroupv1alpha1.HookStatus{
+			Name:           volumegroupv1alpha1.HookPreSnapshot,
+			JobName:        jobName,
+			Phase:          volumegroupv1alpha1.HookPhaseFailed,
+			StartTime:      job.CreationTimestamp.DeepCopy(),
+			CompletionTime: &now,
+			Message:        fmt.Sprintf("Job %s is stopped to run the post-snapshot hooks", jobName),
+		}
+		if status != nil && status.StartTime != nil {
+			newStatus.StartTime = status.StartTime
+		}
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookFailed, newStatus.Message)
+	}
+
+	return false, nil
+}
+
+// runPreSnapshotHooks runs the pre-snapshot Job, then calls the pre-snapshot HTTP endpoint.
+// It returns the status of the hook that is running or completed last, or nil if no pre-snapshot hook is defined.
+func (r *VolumeGroupSnapshotContentReconciler) runPreSnapshotHooks(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (*volumegroupv1alpha1.HookStatus, error) {
+	if vgsc.Spec.Hooks == nil {
+		return nil, nil
+	}
+
+	status, err := r.runHook(ctx, vgsc, volumegroupv1alpha1.HookPreSnapshot, vgsc.Spec.Hooks.PreSnapshot)
+	if err != nil || (status != nil && status.Phase != volumegroupv1alpha1.HookPhaseSucceeded) {
+		return status, err
+	}
+
+	httpStatus, err := r.runHTTPHook(ctx, vgsc, volumegroupv1alpha1.HookPreSnapshotHTTP, vgsc.Spec.Hooks.PreSnapshotHTTP, httpHookPhasePre)
+	if err != nil {
+		return nil, err
+	}
+	if httpStatus != nil {
+		return httpStatus, nil
+	}
+
+	return status, nil
+}
+
+// preSnapshotHooksStarted returns whether any pre-snapshot hook has started, which may have quiesced the applications.
+// It is true if no pre-snapshot hook is defined. The Job is looked up as well, since it may be created without its
+// status being recorded.
+func (r *VolumeGroupSnapshotContentReconciler) preSnapshotHooksStarted(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (bool, error) {
+	hooks := vgsc.Spec.Hooks
+	if hooks == nil || (hooks.PreSnapshot == nil && hooks.PreSnapshotHTTP == nil) {
+		return true, nil
+	}
+
+	if hookStatusFor(vgsc.Status.Hooks, volumegroupv1alpha1.HookPreSnapshot) != nil ||
+		hookStatusFor(vgsc.Status.Hooks, volumegroupv1alpha1.HookPreSnapshotHTTP) != nil {
+		return true, nil
+	}
+
+	if hooks.PreSnapshot == nil {
+		return false, nil
+	}
+
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hookJobName(vgsc, volumegroupv1alpha1.HookPreSnapshot), Namespace: vgsc.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return metav1.IsControlledBy(job, vgsc), nil
+}
+
+// skipPostSnapshotHooks records the post-snapshot hooks that haven't started as skipped
+func skipPostSnapshotHooks(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) {
+	names := []volumegroupv1alpha1.HookName{}
+	if vgsc.Spec.Hooks.PostSnapshotHTTP != nil {
+		names = append(names, volumegroupv1alpha1.HookPostSnapshotHTTP)
+	}
+	if vgsc.Spec.Hooks.PostSnapshot != nil {
+		names = append(names, volumegroupv1alpha1.HookPostSnapshot)
+	}
+
+	now := metav1.Now()
+	for _, name := range names {
+		if hookStatusFor(vgsc.Status.Hooks, name) != nil {
+			continue
+		}
+
+		message := "No pre-snapshot hook has started"
+		setHookStatus(vgsc, volumegroupv1alpha1.HookStatus{
+			Name:           name,
+			Phase:          volumegroupv1alpha1.HookPhaseSkipped,
+			CompletionTime: &now,
+			Message:        message,
+		}, volumegroupv1alpha1.ReasonHookSkipped, message)
+	}
+}
+
+// runPostSnapshotHooks calls the post-snapshot HTTP endpoint, then runs the post-snapshot Job,
+// so that the applications are resumed in the reverse order of the pre-snapshot hooks.
+// They run only after the pre-snapshot Job has completed or is stopped, and the Job is run even if the HTTP endpoint fails.
+// They are skipped if the pre-snapshot hooks are defined but none of them has started.
+// It returns the status of the hook that is running or completed last, or nil if no post-snapshot hook is defined,
+// the pre-snapshot Job is being stopped or the post-snapshot hooks are skipped.
+func (r *VolumeGroupSnapshotContentReconciler) runPostSnapshotHooks(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (*volumegroupv1alpha1.HookStatus, error) {
+	if vgsc.Spec.Hooks == nil || postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		return nil, nil
+	}
+
+	// Deletion of the pre-snapshot Job is watched
+	if stopped, err := r.stopPreSnapshotHook(ctx, vgsc); err != nil || !stopped {
+		return nil, err
+	}
+
+	started, err := r.preSnapshotHooksStarted(ctx, vgsc)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		// The applications aren't quiesced, so they aren't resumed either
+		skipPostSnapshotHooks(vgsc)
+		return nil, nil
+	}
+
+	httpStatus, err := r.runHTTPHook(ctx, vgsc, volumegroupv1alpha1.HookPostSnapshotHTTP, vgsc.Spec.Hooks.PostSnapshotHTTP, httpHookPhasePost)
+	if err != nil || (httpStatus != nil && httpStatus.Phase == volumegroupv1alpha1.HookPhaseRunning) {
+		return httpStatus, err
+	}
+
+	status, err := r.runHook(ctx, vgsc, volumegroupv1alpha1.HookPostSnapshot, vgsc.Spec.Hooks.PostSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		return status, nil
+	}
+
+	return httpStatus, nil
+}
+
+// mirrorHooks copies the results of the hooks of the VolumeGroupSnapshotContent to the VolumeGroupSnapshot
+func mirrorHooks(vgs *volumegroupv1alpha1.VolumeGroupSnapshot, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) {
+	vgs.Status.Hooks = nil
+	for _, status := range vgsc.Status.Hooks {
+		vgs.Status.Hooks = append(vgs.Status.Hooks, *status.DeepCopy())
+	}
+
+	for _, conditionType := range hookConditions {
+		if condition := meta.FindStatusCondition(vgsc.Status.Conditions, conditionType); condition != nil {
+			setCondition(&vgs.Status.Conditions, vgs.Generation, conditionType, condition.Status,
+				condition.Reason, condition.Message)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// createErrorClient fails the creation of any object with the error
+type createErrorClient struct {
+	client.Client
+	err error
+}
+
+func (c *createErrorClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.err
+}
+
+// newHookServiceAccount returns the default ServiceAccount allowed to run the hook Jobs
+func newHookServiceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:        "default",
+		Namespace:   "ns",
+		Annotations: map[string]string{volumegroupv1alpha1.HookServiceAccountAnnotation: "true"},
+	}}
+}
+
+// newHookTestJob returns the Job of the hook controlled by the VolumeGroupSnapshotContent
+func newHookTestJob(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, name volumegroupv1alpha1.HookName) *batchv1.Job {
+	controller := true
+	return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:      hookJobName(vgsc, name),
+		Namespace: vgsc.Namespace,
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: volumegroupv1alpha1.GroupVersion.String(),
+			Kind:       "VolumeGroupSnapshotContent",
+			Name:       vgsc.Name,
+			UID:        vgsc.UID,
+			Controller: &controller,
+		}},
+	}}
+}
+
+func TestRunPostSnapshotHooksSkippedWithoutPreSnapshotHooks(t *testing.T) {
+	stub := &hookStub{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	vgsc := newHookTestContent(&volumegroupv1alpha1.SnapshotHooks{
+		PreSnapshot:      &batchv1.JobTemplateSpec{},
+		PostSnapshot:     &batchv1.JobTemplateSpec{},
+		PostSnapshotHTTP: &volumegroupv1alpha1.HTTPHook{URL: server.URL},
+	})
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, newHookServiceAccount())
+	ctx := context.Background()
+
+	status, err := r.runPostSnapshotHooks(ctx, vgsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != nil {
+		t.Fatalf("expected no post-snapshot hook to run, got %+v", status)
+	}
+
+	for _, name := range []volumegroupv1alpha1.HookName{volumegroupv1alpha1.HookPostSnapshotHTTP, volumegroupv1alpha1.HookPostSnapshot} {
+		if status := hookStatusFor(vgsc.Status.Hooks, name); status == nil || status.Phase != volumegroupv1alpha1.HookPhaseSkipped {
+			t.Fatalf("expected %s to be skipped, got %+v", name, status)
+		}
+	}
+	if !postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		t.Fatal("expected the skipped post-snapshot hooks to be completed")
+	}
+	if len(stub.payloads) != 0 {
+		t.Fatalf("expected the post-snapshot HTTP hook not to be called, got %d calls", len(stub.payloads))
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(newHookTestJob(vgsc, volumegroupv1alpha1.HookPostSnapshot)), &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the post-snapshot Job not to be created, got %v", err)
+	}
+}
+
+func TestRunHookFailsInTerminatingNamespace(t *testing.T) {
+	server := httptest.NewServer(&hookStub{})
+	defer server.Close()
+
+	vgsc := newHookTestContent(&volumegroupv1alpha1.SnapshotHooks{PostSnapshot: &batchv1.JobTemplateSpec{}})
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, newHookServiceAccount())
+
+	forbidden := errors.NewForbidden(batchv1.Resource("jobs"), hookJobName(vgsc, volumegroupv1alpha1.HookPostSnapshot),
+		fmt.Errorf("namespace ns is being terminated"))
+	forbidden.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: corev1.NamespaceTerminatingCause}}
+	r.Client = &createErrorClient{Client: r.Client, err: forbidden}
+
+	status, err := r.runPostSnapshotHooks(context.Background(), vgsc)
+	if err != nil {
+		t.Fatalf("expected the creation error to fail the hook, got %v", err)
+	}
+	if status == nil || status.Phase != volumegroupv1alpha1.HookPhaseFailed {
+		t.Fatalf("expected the hook to fail, got %+v", status)
+	}
+	if !postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		t.Fatal("expected the failed post-snapshot hook to be completed")
+	}
+
+	// Other errors are retried
+	r.Client = &createErrorClient{Client: r.Client, err: errors.NewServiceUnavailable("unavailable")}
+	vgsc.Status.Hooks = nil
+	if _, err := r.runPostSnapshotHooks(context.Background(), vgsc); err == nil {
+		t.Fatal("expected the unavailable error to be returned")
+	}
+}
+
+func TestRunHookPostSnapshotJobTimesOutByDefault(t *testing.T) {
+	server := httptest.NewServer(&hookStub{})
+	defer server.Close()
+
+	vgsc := newHookTestContent(&volumegroupv1alpha1.SnapshotHooks{PostSnapshot: &batchv1.JobTemplateSpec{}})
+	vgsc.UID = "vgsc-uid"
+	job := newHookTestJob(vgsc, volumegroupv1alpha1.HookPostSnapshot)
+	job.CreationTimestamp = metav1.Now()
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, job)
+	ctx := context.Background()
+
+	status, err := r.runHook(ctx, vgsc, volumegroupv1alpha1.HookPostSnapshot, vgsc.Spec.Hooks.PostSnapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Phase != volumegroupv1alpha1.HookPhaseRunning {
+		t.Fatalf("expected the Job to be running, got %+v", status)
+	}
+	if result := requeueForHook(ctrl.Result{}, vgsc.Spec.Hooks, status); result.RequeueAfter <= 0 || result.RequeueAfter > defaultPostSnapshotHookTimeout {
+		t.Fatalf("expected to be requeued by the default timeout, got %+v", result)
+	}
+
+	status.StartTime = &metav1.Time{Time: time.Now().Add(-defaultPostSnapshotHookTimeout)}
+	if status, err = r.runHook(ctx, vgsc, volumegroupv1alpha1.HookPostSnapshot, vgsc.Spec.Hooks.PostSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	if status.Phase != volumegroupv1alpha1.HookPhaseFailed {
+		t.Fatalf("expected the Job to time out, got %+v", status)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), job); !errors.IsNotFound(err) {
+		t.Fatalf("expected the timed out Job to be deleted, got %v", err)
+	}
+
+	// Pre-snapshot Jobs are bounded by the group snapshot instead
+	if _, ok := hookTimeout(vgsc.Spec.Hooks, volumegroupv1alpha1.HookPreSnapshot); ok {
+		t.Fatal("expected no default timeout for the pre-snapshot Job")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// defaultHTTPHookRequestTimeout is used when RequestTimeout of the HTTP hook isn't set
+const defaultHTTPHookRequestTimeout = 10 * time.Second
+
+// maxHTTPHookBackoff is the maximum interval between the calls to the HTTP hook
+const maxHTTPHookBackoff = time.Minute
+
+// Phases of the group snapshot sent to the HTTP hooks
+const (
+	httpHookPhasePre  = "pre"
+	httpHookPhasePost = "post"
+)
+
+// httpHookClient is the client used to call the HTTP hooks unless the reconciler is given one
+var httpHookClient = newHTTPHookClient()
+
+// newHTTPHookClient returns a client that neither follows redirects nor connects to the addresses
+// of the node itself, so that the hooks can't be used to reach the endpoints only the controller can reach
+func newHTTPHookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   httpHookDialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect to the endpoint on behalf of the controller, bypassing the check of the address
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: noHTTPHookRedirect,
+	}
+}
+
+// noHTTPHookRedirect makes the client return the redirect response as it is, which is then reported as a failure
+func noHTTPHookRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// httpHookDialControl rejects the connections to loopback, link-local (such as the cloud metadata endpoints),
+// unspecified and multicast addresses. It checks the resolved address, so that a DNS name can't bypass it.
+func httpHookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%s is not an IP address", host)
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("HTTP hooks are not allowed to connect to %s", ip)
+	}
+
+	return nil
+}
+
+// validateHTTPHookURL returns an error unless the URL of the hook is an absolute http or https URL
+func validateHTTPHookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme of %s is not http or https", rawURL)
+	}
+	if u.Host == "" || u.User != nil {
+		return fmt.Errorf("%s needs a host and no user information", rawURL)
+	}
+
+	return nil
+}
+
+// httpHookPayload is the JSON payload sent to the HTTP hooks
+type httpHookPayload struct {
+	Phase                      string   `json:"phase"`
+	Namespace                  string   `json:"namespace"`
+	VolumeGroupSnapshot        string   `json:"volumeGroupSnapshot,omitempty"`
+	VolumeGroupSnapshotContent string   `json:"volumeGroupSnapshotContent"`
+	PersistentVolumeClaims     []string `json:"persistentVolumeClaims"`
+}
+
+// httpHookPayloadFor returns the payload sent to the HTTP hooks of the VolumeGroupSnapshotContent for the phase
+func httpHookPayloadFor(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, phase string) httpHookPayload {
+	payload := httpHookPayload{
+		Phase:                      phase,
+		Namespace:                  vgsc.Namespace,
+		VolumeGroupSnapshotContent: vgsc.Name,
+		PersistentVolumeClaims:     vgsc.Spec.PersistentVolumeClaimList,
+	}
+	if vgsc.Spec.VolumeGroupSnapshotName != nil {
+		payload.VolumeGroupSnapshot = *vgsc.Spec.VolumeGroupSnapshotName
+	}
+
+	return payload
+}
+
+// httpHookBackoff returns the interval before the next call to the HTTP hook, which doubles on each attempt
+func httpHookBackoff(attempts int32) time.Duration {
+	if attempts > 6 {
+		return maxHTTPHookBackoff
+	}
+
+	if d := time.Second << attempts; d < maxHTTPHookBackoff {
+		return d
+	}
+	return maxHTTPHookBackoff
+}
+
+// callHTTPHook posts the payload to the endpoint of the hook, and returns an error unless it responds with 2xx
+func callHTTPHook(ctx context.Context, httpClient *http.Client, hook *volumegroupv1alpha1.HTTPHook, payload httpHookPayload) error {
+	if err := validateHTTPHookURL(hook.URL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultHTTPHookRequestTimeout
+	if hook.RequestTimeout != nil && hook.RequestTimeout.Duration > 0 {
+		timeout = hook.RequestTimeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Only the beginning of the body is kept, so that the status stays small
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("%s responded with %s: %s", hook.URL, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// httpClient returns the client used to call the HTTP hooks
+func (r *VolumeGroupSnapshotContentReconciler) httpClient() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return httpHookClient
+}
+
+// runHTTPHook calls the endpoint of the hook unless it has completed or is backing off, and records the result.
+// A failed call is retried on the later reconciliations up to Retries times.
+// It returns the status of the hook, or nil if the hook isn't defined.
+func (r *VolumeGroupSnapshotContentReconciler) runHTTPHook(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, name volumegroupv1alpha1.HookName, hook *volumegroupv1alpha1.HTTPHook, phase string) (*volumegroupv1alpha1.HookStatus, error) {
+	if hook == nil {
+		return nil, nil
+	}
+
+	status := hookStatusFor(vgsc.Status.Hooks, name)
+	if status != nil && status.Phase != volumegroupv1alpha1.HookPhaseRunning {
+		// Hooks are run only once
+		return status, nil
+	}
+
+	now := metav1.Now()
+	if status != nil && status.LastAttemptTime != nil && now.Time.Before(status.LastAttemptTime.Add(httpHookBackoff(status.Attempts))) {
+		// Reconciled by another event before the next attempt
+		return status, nil
+	}
+
+	newStatus := volumegroupv1alpha1.HookStatus{
+		Name:      name,
+		Phase:     volumegroupv1alpha1.HookPhaseRunning,
+		StartTime: &now,
+	}
+	if status != nil {
+		newStatus = *status.DeepCopy()
+	}
+
+	if deadline, ok := hookDeadline(vgsc.Spec.Hooks, &newStatus); ok && !now.Time.Before(deadline) {
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseFailed
+		newStatus.CompletionTime = &now
+		newStatus.Message = fmt.Sprintf("%s did not succeed within %s: %s", hook.URL, vgsc.Spec.Hooks.Timeout.Duration, newStatus.Message)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookTimeout, newStatus.Message)
+		return hookStatusFor(vgsc.Status.Hooks, name), nil
+	}
+
+	err := callHTTPHook(ctx, r.httpClient(), hook, httpHookPayloadFor(vgsc, phase))
+	newStatus.Attempts++
+	newStatus.LastAttemptTime = &now
+
+	switch {
+	case err == nil:
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseSucceeded
+		newStatus.CompletionTime = &now
+		newStatus.Message = ""
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookSucceeded, fmt.Sprintf("%s succeeded", hook.URL))
+	case newStatus.Attempts > hook.Retries:
+		newStatus.Phase = volumegroupv1alpha1.HookPhaseFailed
+		newStatus.CompletionTime = &now
+		newStatus.Message = fmt.Sprintf("%s failed after %d attempts: %v", hook.URL, newStatus.Attempts, err)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookFailed, newStatus.Message)
+	default:
+		newStatus.Message = fmt.Sprintf("Attempt %d failed: %v", newStatus.Attempts, err)
+		setHookStatus(vgsc, newStatus, volumegroupv1alpha1.ReasonHookRetrying, newStatus.Message)
+	}
+
+	return hookStatusFor(vgsc.Status.Hooks, name), nil
+}
+
+// resumeBeforeDeletion runs the post-snapshot hooks of the deleted VolumeGroupSnapshotContent until they complete,
+// so that the applications aren't left quiesced
+func (r *VolumeGroupSnapshotContentReconciler) resumeBeforeDeletion(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (ctrl.Result, error) {
+	if postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := vgsc.Status.DeepCopy()
+
+	status, err := r.runPostSnapshotHooks(ctx, vgsc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Progress of the Job is watched, while the HTTP endpoint is retried
+	return requeueForHook(ctrl.Result{}, vgsc.Spec.Hooks, status), nil
+}
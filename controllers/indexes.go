@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+const (
+	// snapshotListIndex indexes VolumeGroupSnapshotContents by the names of the VolumeSnapshots in SnapshotList
+	snapshotListIndex = "spec.snapshotList"
+
+	// boundContentNameIndex indexes VolumeGroupSnapshots by BoundVolumeGroupSnapshotContentName
+	boundContentNameIndex = "spec.boundVolumeGroupSnapshotContentName"
+
+	// volumeGroupNameIndex indexes VolumeGroupSnapshots by VolumeGroupName
+	volumeGroupNameIndex = "spec.volumeGroupName"
+
+	// volumeSnapshotOwnerIndex indexes VolumeSnapshots by the name of the VolumeGroupSnapshotContent controlling them
+	volumeSnapshotOwnerIndex = "metadata.ownerReferences.volumeGroupSnapshotContent"
+
+	// volumeSnapshotSourcePVCIndex indexes VolumeSnapshots by the name of their source PersistentVolumeClaim
+	volumeSnapshotSourcePVCIndex = "spec.source.persistentVolumeClaimName"
+
+	// volumeSnapshotRefNamespaceIndex indexes VolumeSnapshotContents by the namespace of the VolumeSnapshot they are bound to
+	volumeSnapshotRefNamespaceIndex = "spec.volumeSnapshotRef.namespace"
+
+	// volumeSnapshotContentNamesIndex indexes ClusterVolumeGroupSnapshotContents by VolumeSnapshotContentNames
+	volumeSnapshotContentNamesIndex = "spec.volumeSnapshotContentNames"
+)
+
+// fieldIndex is a field index of the objects of a type
+type fieldIndex struct {
+	obj     client.Object
+	field   string
+	extract client.IndexerFunc
+}
+
+// fieldIndexes are the field indexes shared by the reconcilers
+var fieldIndexes = []fieldIndex{
+	{
+		obj:   &volumegroupv1alpha1.VolumeGroupSnapshotContent{},
+		field: snapshotListIndex,
+		extract: func(obj client.Object) []string {
+			return obj.(*volumegroupv1alpha1.VolumeGroupSnapshotContent).Spec.SnapshotList
+		},
+	},
+	{
+		obj:   &snapshotv1.VolumeSnapshot{},
+		field: volumeSnapshotOwnerIndex,
+		extract: func(obj client.Object) []string {
+			owner := metav1.GetControllerOf(obj)
+			if owner == nil || owner.APIVersion != volumegroupv1alpha1.GroupVersion.String() || owner.Kind != "VolumeGroupSnapshotContent" {
+				return nil
+			}
+			return []string{owner.Name}
+		},
+	},
+	{
+		obj:   &snapshotv1.VolumeSnapshot{},
+		field: volumeSnapshotSourcePVCIndex,
+		extract: func(obj client.Object) []string {
+			vs := obj.(*snapshotv1.VolumeSnapshot)
+			if vs.Spec.Source.PersistentVolumeClaimName == nil {
+				return nil
+			}
+			return []string{*vs.Spec.Source.PersistentVolumeClaimName}
+		},
+	},
+	{
+		obj:   &snapshotv1.VolumeSnapshotContent{},
+		field: volumeSnapshotRefNamespaceIndex,
+		extract: func(obj client.Object) []string {
+			vsc := obj.(*snapshotv1.VolumeSnapshotContent)
+			if vsc.Spec.VolumeSnapshotRef.Namespace == "" {
+				return nil
+			}
+			return []string{vsc.Spec.VolumeSnapshotRef.Namespace}
+		},
+	},
+	{
+		obj:   &volumegroupv1alpha1.VolumeGroupSnapshot{},
+		field: boundContentNameIndex,
+		extract: func(obj client.Object) []string {
+			vgs := obj.(*volumegroupv1alpha1.VolumeGroupSnapshot)
+			if vgs.Spec.BoundVolumeGroupSnapshotContentName == nil {
+				return nil
+			}
+			return []string{*vgs.Spec.BoundVolumeGroupSnapshotContentName}
+		},
+	},
+	{
+		obj:   &volumegroupv1alpha1.VolumeGroupSnapshot{},
+		field: volumeGroupNameIndex,
+		extract: func(obj client.Object) []string {
+			vgs := obj.(*volumegroupv1alpha1.VolumeGroupSnapshot)
+			if vgs.Spec.VolumeGroupName == nil {
+				return nil
+			}
+			return []string{*vgs.Spec.VolumeGroupName}
+		},
+	},
+	{
+		obj:   &volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent{},
+		field: volumeSnapshotContentNamesIndex,
+		extract: func(obj client.Object) []string {
+			return obj.(*volumegroupv1alpha1.ClusterVolumeGroupSnapshotContent).Spec.VolumeSnapshotContentNames
+		},
+	},
+}
+
+// SetupIndexes registers the field indexes shared by the reconcilers to the Manager
+func SetupIndexes(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+
+	for _, index := range fieldIndexes {
+		if err := indexer.IndexField(ctx, index.obj, index.field, index.extract); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// volumeGroupSnapshotContentsFor returns the VolumeGroupSnapshotContents listing the VolumeSnapshot in SnapshotList
+func volumeGroupSnapshotContentsFor(ctx context.Context, c client.Client, namespace, vsName string) ([]volumegroupv1alpha1.VolumeGroupSnapshotContent, error) {
+	vgscList := &volumegroupv1alpha1.VolumeGroupSnapshotContentList{}
+	if err := c.List(ctx, vgscList, client.InNamespace(namespace), client.MatchingFields{snapshotListIndex: vsName}); err != nil {
+		return nil, err
+	}
+
+	return vgscList.Items, nil
+}
+
+// volumeSnapshotsForPVC returns the VolumeSnapshots taken from the PersistentVolumeClaim
+func volumeSnapshotsForPVC(ctx context.Context, c client.Client, namespace, pvcName string) ([]snapshotv1.VolumeSnapshot, error) {
+	vsList := &snapshotv1.VolumeSnapshotList{}
+	if err := c.List(ctx, vsList, client.InNamespace(namespace), client.MatchingFields{volumeSnapshotSourcePVCIndex: pvcName}); err != nil {
+		return nil, err
+	}
+
+	return vsList.Items, nil
+}
+
+// boundVolumeSnapshotContents returns the VolumeSnapshotContents bound to the VolumeSnapshots in the namespace by their names
+func boundVolumeSnapshotContents(ctx context.Context, c client.Client, namespace string) (map[string]*snapshotv1.VolumeSnapshotContent, error) {
+	vscList := &snapshotv1.VolumeSnapshotContentList{}
+	if err := c.List(ctx, vscList, client.MatchingFields{volumeSnapshotRefNamespaceIndex: namespace}); err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string]*snapshotv1.VolumeSnapshotContent, len(vscList.Items))
+	for i := range vscList.Items {
+		contents[vscList.Items[i].Name] = &vscList.Items[i]
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// workloadPollInterval is the interval to check whether the Pods of the scaled down workloads are gone
+const workloadPollInterval = 5 * time.Second
+
+// newWorkload returns an empty object of the kind of the workload
+func newWorkload(kind string) (client.Object, error) {
+	switch kind {
+	case volumegroupv1alpha1.WorkloadKindDeployment:
+		return &appsv1.Deployment{}, nil
+	case volumegroupv1alpha1.WorkloadKindStatefulSet:
+		return &appsv1.StatefulSet{}, nil
+	}
+	return nil, fmt.Errorf("unsupported workload kind %s", kind)
+}
+
+// workloadReplicas returns the desired replicas and the Pod selector of the workload
+func workloadReplicas(obj client.Object) (*int32, *metav1.LabelSelector) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return workload.Spec.Replicas, workload.Spec.Selector
+	case *appsv1.StatefulSet:
+		return workload.Spec.Replicas, workload.Spec.Selector
+	}
+	return nil, nil
+}
+
+// setWorkloadReplicas sets the desired replicas of the workload
+func setWorkloadReplicas(obj client.Object, replicas int32) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		workload.Spec.Replicas = &replicas
+	case *appsv1.StatefulSet:
+		workload.Spec.Replicas = &replicas
+	}
+}
+
+// workloadStatusFor returns the recorded status of the workload, or nil if it isn't scaled down yet
+func workloadStatusFor(statuses []volumegroupv1alpha1.WorkloadStatus, ref volumegroupv1alpha1.ScalableWorkloadReference) *volumegroupv1alpha1.WorkloadStatus {
+	for i := range statuses {
+		if statuses[i].Kind == ref.Kind && statuses[i].Name == ref.Name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// workloadsRestored returns whether the workloads scaled down for the group snapshot are restored.
+// It is true if the workloads aren't scaled down for the group snapshot.
+func workloadsRestored(quiesce volumegroupv1alpha1.QuiesceMode, conditions []metav1.Condition) bool {
+	if quiesce != volumegroupv1alpha1.QuiesceModeScaleDown {
+		return true
+	}
+
+	quiesced := meta.FindStatusCondition(conditions, volumegroupv1alpha1.ConditionQuiesced)
+	return quiesced != nil && quiesced.Reason == volumegroupv1alpha1.ReasonRestored
+}
+
+// getWorkload gets the workload in the namespace of the VolumeGroupSnapshotContent
+func (r *VolumeGroupSnapshotContentReconciler) getWorkload(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, kind, name string) (client.Object, error) {
+	obj, err := newWorkload(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: vgsc.Namespace}, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// patchWorkloadReplicas changes the desired replicas of the workload
+func (r *VolumeGroupSnapshotContentReconciler) patchWorkloadReplicas(ctx context.Context, obj client.Object, replicas int32) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	setWorkloadReplicas(obj, replicas)
+	return r.Patch(ctx, obj, patch)
+}
+
+// workloadPodCount returns the number of the Pods controlled by the Deployment, through its ReplicaSets,
+// or by the StatefulSet. Pods of the other workloads matching the same selector aren't counted.
+func (r *VolumeGroupSnapshotContentReconciler) workloadPodCount(ctx context.Context, obj client.Object) (int, error) {
+	_, selector := workloadReplicas(obj)
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	owners := map[types.UID]bool{}
+	if _, ok := obj.(*appsv1.Deployment); ok {
+		rsList := &appsv1.ReplicaSetList{}
+		if err := r.List(ctx, rsList, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+			return 0, err
+		}
+		for i := range rsList.Items {
+			if metav1.IsControlledBy(&rsList.Items[i], obj) {
+				owners[rsList.Items[i].UID] = true
+			}
+		}
+	} else {
+		owners[obj.GetUID()] = true
+	}
+
+	// Terminating Pods may still use the volumes, so they are counted as well
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+		return 0, err
+	}
+
+	pods := 0
+	for i := range podList.Items {
+		if ref := metav1.GetControllerOf(&podList.Items[i]); ref != nil && owners[ref.UID] {
+			pods++
+		}
+	}
+
+	return pods, nil
+}
+
+// scaleDownWorkloads records the replicas of the workloads, then scales them to zero.
+// It returns whether all the Pods of the workloads are gone, and the reason and the message
+// if a workload isn't found or doesn't mount any PersistentVolumeClaim of the group.
+func (r *VolumeGroupSnapshotContentReconciler) scaleDownWorkloads(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (bool, string, string, error) {
+	if vgsc.Spec.Quiesce != volumegroupv1alpha1.QuiesceModeScaleDown {
+		return true, "", "", nil
+	}
+
+	workloads := make([]client.Object, 0, len(vgsc.Spec.Workloads))
+	recorded := false
+	for _, ref := range vgsc.Spec.Workloads {
+		obj, err := r.getWorkload(ctx, vgsc, ref.Kind, ref.Name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, volumegroupv1alpha1.ReasonWorkloadNotFound, fmt.Sprintf("%s %s is not found", ref.Kind, ref.Name), nil
+			}
+			return false, "", "", err
+		}
+		workloads = append(workloads, obj)
+
+		if workloadStatusFor(vgsc.Status.Workloads, ref) != nil {
+			continue
+		}
+
+		// Only the workloads using the group are scaled down, which are checked before they are first scaled down
+		if !workloadMountsPVC(obj, vgsc.Spec.PersistentVolumeClaimList) {
+			return false, volumegroupv1alpha1.ReasonWorkloadNotMember,
+				fmt.Sprintf("%s %s doesn't mount any PersistentVolumeClaim of the group", ref.Kind, ref.Name), nil
+		}
+
+		replicas := int32(1)
+		if current, _ := workloadReplicas(obj); current != nil {
+			replicas = *current
+		}
+		vgsc.Status.Workloads = append(vgsc.Status.Workloads, volumegroupv1alpha1.WorkloadStatus{
+			Kind:     ref.Kind,
+			Name:     ref.Name,
+			Replicas: replicas,
+		})
+		recorded = true
+	}
+
+	if recorded {
+		// Persist the replicas before scaling down, so that they can be restored after a restart
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionQuiesced, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonScalingDown, fmt.Sprintf("Scaling down %d workloads", len(vgsc.Spec.Workloads)))
+		vgsc.Status.ObservedGeneration = vgsc.Generation
+		if err := r.Status().Update(ctx, vgsc); err != nil {
+			return false, "", "", err
+		}
+	}
+
+	pods := 0
+	for i, obj := range workloads {
+		if workloadStatusFor(vgsc.Status.Workloads, vgsc.Spec.Workloads[i]).Restored {
+			// Restored workloads aren't scaled down again
+			continue
+		}
+
+		if replicas, _ := workloadReplicas(obj); replicas == nil || *replicas != 0 {
+			if err := r.patchWorkloadReplicas(ctx, obj, 0); err != nil {
+				return false, "", "", err
+			}
+		}
+
+		count, err := r.workloadPodCount(ctx, obj)
+		if err != nil {
+			return false, "", "", err
+		}
+		pods += count
+	}
+
+	if pods > 0 {
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionQuiesced, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonScalingDown, fmt.Sprintf("Waiting for %d Pods of the workloads to be deleted", pods))
+		return false, "", "", nil
+	}
+
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionQuiesced, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonScaledDown, fmt.Sprintf("%d workloads are scaled down", len(vgsc.Spec.Workloads)))
+	return true, "", "", nil
+}
+
+// restoreWorkloads restores the recorded replicas of the scaled down workloads.
+// Workloads scaled up by others in the meantime are left as they are.
+func (r *VolumeGroupSnapshotContentReconciler) restoreWorkloads(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) error {
+	if workloadsRestored(vgsc.Spec.Quiesce, vgsc.Status.Conditions) {
+		return nil
+	}
+
+	for i := range vgsc.Status.Workloads {
+		status := &vgsc.Status.Workloads[i]
+		if status.Restored {
+			continue
+		}
+
+		obj, err := r.getWorkload(ctx, vgsc, status.Kind, status.Name)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		if err == nil {
+			if replicas, _ := workloadReplicas(obj); replicas != nil && *replicas == 0 {
+				if err := r.patchWorkloadReplicas(ctx, obj, status.Replicas); err != nil {
+					return err
+				}
+			}
+		}
+		status.Restored = true
+	}
+
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionQuiesced, metav1.ConditionFalse,
+		volumegroupv1alpha1.ReasonRestored, fmt.Sprintf("Replicas of %d workloads are restored", len(vgsc.Status.Workloads)))
+	return nil
+}
+
+// resume restores the scaled down workloads, then runs the post-snapshot hooks
+// of the ready or failed VolumeGroupSnapshotContent until they complete
+func (r *VolumeGroupSnapshotContentReconciler) resume(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (ctrl.Result, error) {
+	if workloadsRestored(vgsc.Spec.Quiesce, vgsc.Status.Conditions) && postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := vgsc.Status.DeepCopy()
+
+	if err := r.restoreWorkloads(ctx, vgsc); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status, err := r.runPostSnapshotHooks(ctx, vgsc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Progress of the Job is watched, while the HTTP endpoint is retried
+	return requeueForHook(ctrl.Result{}, vgsc.Spec.Hooks, status), nil
+}
+
+// mirrorQuiesced copies the Quiesced condition of the VolumeGroupSnapshotContent to the VolumeGroupSnapshot
+func mirrorQuiesced(vgs *volumegroupv1alpha1.VolumeGroupSnapshot, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) {
+	if quiesced := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionQuiesced); quiesced != nil {
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionQuiesced, quiesced.Status,
+			quiesced.Reason, quiesced.Message)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+func TestResumeAfterFailure(t *testing.T) {
+	stub := &hookStub{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	replicas := int32(0)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	vgsc := newHookTestContent(&volumegroupv1alpha1.SnapshotHooks{
+		PreSnapshotHTTP:  &volumegroupv1alpha1.HTTPHook{URL: server.URL},
+		PostSnapshotHTTP: &volumegroupv1alpha1.HTTPHook{URL: server.URL},
+	})
+	vgsc.Spec.Quiesce = volumegroupv1alpha1.QuiesceModeScaleDown
+	vgsc.Spec.Workloads = []volumegroupv1alpha1.ScalableWorkloadReference{{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "app"}}
+	vgsc.Status.Workloads = []volumegroupv1alpha1.WorkloadStatus{{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "app", Replicas: 3}}
+	vgsc.Status.Hooks = []volumegroupv1alpha1.HookStatus{{Name: volumegroupv1alpha1.HookPreSnapshotHTTP, Phase: volumegroupv1alpha1.HookPhaseFailed}}
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionQuiesced, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonScaledDown, "1 workloads are scaled down")
+	setContentFailed(vgsc, volumegroupv1alpha1.ReasonHookFailed, "pre-snapshot hook failed")
+
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, deployment)
+	ctx := context.Background()
+
+	result, err := r.resume(ctx, vgsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue after the post-snapshot hook succeeded, got %+v", result)
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: "app", Namespace: "ns"}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if *deployment.Spec.Replicas != 3 {
+		t.Fatalf("expected the replicas to be restored to 3, got %d", *deployment.Spec.Replicas)
+	}
+	if len(stub.payloads) != 1 || stub.payloads[0].Phase != httpHookPhasePost {
+		t.Fatalf("expected the post-snapshot hook to be called once, got %+v", stub.payloads)
+	}
+
+	stored := storedContent(t, r, vgsc)
+	if !workloadsRestored(stored.Spec.Quiesce, stored.Status.Conditions) || !postSnapshotHooksCompleted(stored.Spec.Hooks, stored.Status.Hooks) {
+		t.Fatalf("expected the resumed status to be persisted, got %+v", stored.Status)
+	}
+
+	// Resumed contents aren't resumed again
+	if _, err := r.resume(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.payloads) != 1 {
+		t.Fatalf("expected the post-snapshot hook not to be called again, got %d calls", len(stub.payloads))
+	}
+}
+
+func TestResumeUntilTimeout(t *testing.T) {
+	stub := &hookStub{failures: 10}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	vgsc := newHookTestContent(&volumegroupv1alpha1.SnapshotHooks{
+		PostSnapshotHTTP: &volumegroupv1alpha1.HTTPHook{URL: server.URL, Retries: 10},
+		Timeout:          &metav1.Duration{Duration: time.Minute},
+	})
+	setContentFailed(vgsc, volumegroupv1alpha1.ReasonTimeout, "group snapshot timed out")
+
+	r, vgsc := newFakeContentReconciler(t, server, vgsc)
+	ctx := context.Background()
+
+	result, err := r.resume(ctx, vgsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Fatalf("expected the failed post-snapshot hook to be retried, got %+v", result)
+	}
+
+	vgsc = storedContent(t, r, vgsc)
+	status := hookStatusFor(vgsc.Status.Hooks, volumegroupv1alpha1.HookPostSnapshotHTTP)
+	if status == nil || status.Phase != volumegroupv1alpha1.HookPhaseRunning {
+		t.Fatalf("expected the running hook to be persisted, got %+v", vgsc.Status.Hooks)
+	}
+	status.StartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	expireBackoff(vgsc, volumegroupv1alpha1.HookPostSnapshotHTTP)
+
+	if result, err = r.resume(ctx, vgsc); err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue after the post-snapshot hook timed out, got %+v", result)
+	}
+	if !postSnapshotHooksCompleted(vgsc.Spec.Hooks, storedContent(t, r, vgsc).Status.Hooks) {
+		t.Fatal("expected the timed out post-snapshot hook to be completed")
+	}
+}
+
+// newScaleDownTestDeployment returns a Deployment mounting the PersistentVolumeClaim with its ReplicaSet and Pod
+func newScaleDownTestDeployment(name, pvcName string) (*appsv1.Deployment, *appsv1.ReplicaSet, *corev1.Pod) {
+	replicas := int32(2)
+	labels := map[string]string{"app": "shared"}
+	controller := true
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name + "-uid")},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}},
+			}}}},
+		},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: name + "-rs", Namespace: "ns", UID: types.UID(name + "-rs-uid"), Labels: labels,
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: name, UID: deployment.UID, Controller: &controller}},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: name + "-pod", Namespace: "ns", Labels: labels,
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID, Controller: &controller}},
+	}}
+
+	return deployment, rs, pod
+}
+
+func TestScaleDownWorkloadsRejectsNonMembers(t *testing.T) {
+	server := httptest.NewServer(&hookStub{})
+	defer server.Close()
+
+	deployment, rs, pod := newScaleDownTestDeployment("other", "other-pvc")
+	vgsc := newHookTestContent(nil)
+	vgsc.Spec.Quiesce = volumegroupv1alpha1.QuiesceModeScaleDown
+	vgsc.Spec.Workloads = []volumegroupv1alpha1.ScalableWorkloadReference{{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "other"}}
+
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, deployment, rs, pod)
+	ctx := context.Background()
+
+	scaledDown, reason, _, err := r.scaleDownWorkloads(ctx, vgsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scaledDown || reason != volumegroupv1alpha1.ReasonWorkloadNotMember {
+		t.Fatalf("expected the workload to be rejected, got %v %s", scaledDown, reason)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), deployment); err != nil {
+		t.Fatal(err)
+	}
+	if *deployment.Spec.Replicas != 2 || len(vgsc.Status.Workloads) != 0 {
+		t.Fatalf("expected the workload not to be scaled down, got %d replicas", *deployment.Spec.Replicas)
+	}
+}
+
+func TestScaleDownWorkloadsCountsOwnedPods(t *testing.T) {
+	server := httptest.NewServer(&hookStub{})
+	defer server.Close()
+
+	// Both Deployments select the same labels, but only the Pod of the member is waited for
+	member, memberRS, memberPod := newScaleDownTestDeployment("member", "pvc-1")
+	other, otherRS, otherPod := newScaleDownTestDeployment("other", "other-pvc")
+	vgsc := newHookTestContent(nil)
+	vgsc.Spec.Quiesce = volumegroupv1alpha1.QuiesceModeScaleDown
+	vgsc.Spec.Workloads = []volumegroupv1alpha1.ScalableWorkloadReference{{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "member"}}
+
+	r, vgsc := newFakeContentReconciler(t, server, vgsc, member, memberRS, memberPod, other, otherRS, otherPod)
+	ctx := context.Background()
+
+	scaledDown, reason, _, err := r.scaleDownWorkloads(ctx, vgsc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scaledDown || reason != "" {
+		t.Fatalf("expected to wait for the Pod of the member, got %v %s", scaledDown, reason)
+	}
+	if status := workloadStatusFor(vgsc.Status.Workloads, vgsc.Spec.Workloads[0]); status == nil || status.Replicas != 2 {
+		t.Fatalf("expected the replicas to be recorded, got %+v", vgsc.Status.Workloads)
+	}
+
+	if err := r.Delete(ctx, memberPod); err != nil {
+		t.Fatal(err)
+	}
+	if scaledDown, _, _, err = r.scaleDownWorkloads(ctx, vgsc); err != nil {
+		t.Fatal(err)
+	}
+	if !scaledDown {
+		t.Fatal("expected the Pod of the other Deployment not to be waited for")
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(member), member); err != nil {
+		t.Fatal(err)
+	}
+	if *member.Spec.Replicas != 0 {
+		t.Fatalf("expected the member to be scaled down, got %d replicas", *member.Spec.Replicas)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// isDefaultSnapshotClassAnnotation is the annotation to mark a VolumeSnapshotClass as the default for its driver
+const isDefaultSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+
+// snapshotClassError describes why the VolumeSnapshotClass for a PersistentVolumeClaim can't be decided,
+// with the reason of the condition reporting it
+type snapshotClassError struct {
+	reason  string
+	message string
+}
+
+func (e *snapshotClassError) Error() string {
+	return e.message
+}
+
+// asSnapshotClassError returns the snapshotClassError wrapped in err, if any
+func asSnapshotClassError(err error) (*snapshotClassError, bool) {
+	classErr := &snapshotClassError{}
+	if errors.As(err, &classErr) {
+		return classErr, true
+	}
+	return nil, false
+}
+
+// asMembersNotFoundError returns the membersNotFoundError wrapped in err, if any
+func asMembersNotFoundError(err error) (*membersNotFoundError, bool) {
+	notFoundErr := &membersNotFoundError{}
+	if errors.As(err, &notFoundErr) {
+		return notFoundErr, true
+	}
+	return nil, false
+}
+
+// csiDriverFor returns the name of the CSI driver of the PersistentVolume bound to the PersistentVolumeClaim
+func csiDriverFor(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	if pvc.Spec.VolumeName == "" {
+		return "", &snapshotClassError{
+			reason:  volumegroupv1alpha1.ReasonVolumeNotBound,
+			message: fmt.Sprintf("PersistentVolumeClaim %s/%s is not bound to a PersistentVolume", pvc.Namespace, pvc.Name),
+		}
+	}
+
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return "", err
+	}
+
+	if pv.Spec.CSI == nil {
+		return "", &snapshotClassError{
+			reason:  volumegroupv1alpha1.ReasonNotCSIVolume,
+			message: fmt.Sprintf("PersistentVolume %s for %s/%s is not provisioned by a CSI driver", pv.Name, pvc.Namespace, pvc.Name),
+		}
+	}
+
+	return pv.Spec.CSI.Driver, nil
+}
+
+// defaultVolumeSnapshotClassFor returns the name of the default VolumeSnapshotClass for the CSI driver of the PersistentVolumeClaim
+func defaultVolumeSnapshotClassFor(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) (*string, error) {
+	driver, err := csiDriverFor(ctx, c, pvc)
+	if err != nil {
+		return nil, err
+	}
+
+	classList := &snapshotv1.VolumeSnapshotClassList{}
+	if err := c.List(ctx, classList); err != nil {
+		return nil, err
+	}
+
+	defaultClasses := []string{}
+	for _, class := range classList.Items {
+		if class.Driver == driver && class.Annotations[isDefaultSnapshotClassAnnotation] == "true" {
+			defaultClasses = append(defaultClasses, class.Name)
+		}
+	}
+
+	if len(defaultClasses) == 0 {
+		return nil, &snapshotClassError{
+			reason:  volumegroupv1alpha1.ReasonNoDefaultSnapshotClass,
+			message: fmt.Sprintf("no default VolumeSnapshotClass found for driver %s of %s/%s", driver, pvc.Namespace, pvc.Name),
+		}
+	}
+	if len(defaultClasses) > 1 {
+		return nil, &snapshotClassError{
+			reason:  volumegroupv1alpha1.ReasonMultipleDefaultSnapshotClasses,
+			message: fmt.Sprintf("%d default VolumeSnapshotClasses found for driver %s of %s/%s", len(defaultClasses), driver, pvc.Namespace, pvc.Name),
+		}
+	}
+
+	return &defaultClasses[0], nil
+}
+
+// setCondition sets the condition of the type in conditions, recording the generation it was observed for
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// deadlineFor returns the time by which the object needs to become ready, and false if it has no deadline.
+// timeout overrides defaultTimeout, and zero means no timeout.
+func deadlineFor(obj metav1.Object, timeout *metav1.Duration, defaultTimeout time.Duration) (time.Time, bool) {
+	d := defaultTimeout
+	if timeout != nil {
+		d = timeout.Duration
+	}
+
+	if d <= 0 {
+		return time.Time{}, false
+	}
+
+	return obj.GetCreationTimestamp().Add(d), true
+}
+
+// requeueAtDeadline returns the result to reconcile the object again when its deadline passes.
+// Objects without deadline are reconciled again only on the events of the watched objects.
+func requeueAtDeadline(obj metav1.Object, timeout *metav1.Duration, defaultTimeout time.Duration) ctrl.Result {
+	return requeueUntil(deadlineFor(obj, timeout, defaultTimeout))
+}
+
+// requeueUntil returns the result to reconcile again when the deadline passes, and the empty result if there is no deadline.
+func requeueUntil(deadline time.Time, ok bool) ctrl.Result {
+	if !ok {
+		return ctrl.Result{}
+	}
+
+	if d := time.Until(deadline); d > 0 {
+		return ctrl.Result{RequeueAfter: d}
+	}
+	return ctrl.Result{Requeue: true}
+}
+
+// createConcurrently calls create for each of the count objects, at most parallelism at a time.
+// All the calls are released together so that they are issued as close together as possible.
+// It returns the time each creation was issued, and the conflicts and the errors returned by create.
+func createConcurrently(count, parallelism int, create func(i int) (string, error)) ([]time.Time, []string, []error) {
+	if parallelism <= 0 {
+		parallelism = count
+	}
+
+	issued := make([]time.Time, count)
+	conflicts := make([]string, count)
+	errs := make([]error, count)
+	sem := make(chan struct{}, parallelism)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Issued when the request is sent, not when its response is received
+			issued[i] = time.Now()
+			conflicts[i], errs[i] = create(i)
+		}(i)
+	}
+	// Release all the goroutines together once they are ready
+	close(start)
+	wg.Wait()
+
+	return issued, conflicts, errs
+}
+
+// generatedName joins the parts into the name of a generated object. A name longer than the limit of
+// the object names is truncated and suffixed with a hash of the full name, so that it stays unique.
+func generatedName(parts ...string) string {
+	name := strings.Join(parts, "-")
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:])[:10]
+	return fmt.Sprintf("%s-%s", strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)-1], "-."), suffix)
+}
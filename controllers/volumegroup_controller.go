@@ -0,0 +1,330 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// VolumeGroupReconciler reconciles a VolumeGroup object
+type VolumeGroupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets;deployments;replicasets,verbs=get;list;watch
+
+// Reconcile is reconciliation loop for VolumeGroup
+func (r *VolumeGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	vg := &volumegroupv1alpha1.VolumeGroup{}
+	if err := r.Get(ctx, req.NamespacedName, vg); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found. Ignore this
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	status, err := r.volumeGroupStatusFor(ctx, vg)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if equality.Semantic.DeepEqual(vg.Status, *status) {
+		// Status is up to date
+		return ctrl.Result{}, nil
+	}
+
+	vg.Status = *status
+	if err := r.Status().Update(ctx, vg); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *VolumeGroupReconciler) volumeGroupStatusFor(ctx context.Context, vg *volumegroupv1alpha1.VolumeGroup) (*volumegroupv1alpha1.VolumeGroupStatus, error) {
+	pvcs, missing, err := volumeGroupMembers(ctx, r.Client, vg)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCapacity := resource.Quantity{}
+	status := &volumegroupv1alpha1.VolumeGroupStatus{
+		ObservedGeneration:                vg.Generation,
+		MemberCount:                       int32(len(pvcs)),
+		Members:                           []volumegroupv1alpha1.VolumeGroupMember{},
+		MissingPersistentVolumeClaimNames: missing,
+		Conditions:                        vg.Status.DeepCopy().Conditions,
+	}
+
+	// Missing PVCs are reported while the resolved members are kept up to date
+	if len(missing) > 0 {
+		setCondition(&status.Conditions, vg.Generation, volumegroupv1alpha1.ConditionMembersResolved, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonMemberNotFound, (&membersNotFoundError{volumeGroup: vg.Name, names: missing}).Error())
+	} else {
+		setCondition(&status.Conditions, vg.Generation, volumegroupv1alpha1.ConditionMembersResolved, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonResolved, fmt.Sprintf("%d PersistentVolumeClaims are resolved", len(pvcs)))
+	}
+
+	for _, pvc := range pvcs {
+		member := volumegroupv1alpha1.VolumeGroupMember{
+			PersistentVolumeClaimName: pvc.Name,
+			Phase:                     pvc.Status.Phase,
+			StorageClassName:          pvc.Spec.StorageClassName,
+		}
+
+		if capacity, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			memberCapacity := capacity.DeepCopy()
+			member.Capacity = &memberCapacity
+			totalCapacity.Add(capacity)
+		}
+
+		// Driver is only known once the PVC is bound to a CSI volume
+		if pvc.Spec.VolumeName != "" {
+			pv := &corev1.PersistentVolume{}
+			if err := r.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, err
+				}
+			} else if pv.Spec.CSI != nil {
+				member.Driver = &pv.Spec.CSI.Driver
+			}
+		}
+
+		status.Members = append(status.Members, member)
+	}
+	status.TotalCapacity = &totalCapacity
+
+	return status, nil
+}
+
+// membersNotFoundError reports the PersistentVolumeClaims listed in the VolumeGroup that don't exist
+type membersNotFoundError struct {
+	volumeGroup string
+	names       []string
+}
+
+func (e *membersNotFoundError) Error() string {
+	return fmt.Sprintf("PersistentVolumeClaims %s listed in VolumeGroup %s are not found", strings.Join(e.names, ", "), e.volumeGroup)
+}
+
+// volumeGroupMembers returns the PersistentVolumeClaims that belong to the VolumeGroup, sorted by name,
+// and the names of the PersistentVolumeClaims listed in the VolumeGroup that don't exist
+func volumeGroupMembers(ctx context.Context, c client.Client, vg *volumegroupv1alpha1.VolumeGroup) ([]corev1.PersistentVolumeClaim, []string, error) {
+	members := map[string]corev1.PersistentVolumeClaim{}
+	missing := []string{}
+
+	excludeSelector := labels.Nothing()
+	if vg.Spec.ExcludeSelector != nil {
+		var err error
+		excludeSelector, err = metav1.LabelSelectorAsSelector(vg.Spec.ExcludeSelector)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Add PVCs matched by the label selector, except for the excluded ones.
+	// A nil selector matches nothing.
+	if vg.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(vg.Spec.Selector)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		if err := c.List(ctx, pvcList, client.InNamespace(vg.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, err
+		}
+
+		for _, pvc := range pvcList.Items {
+			if excludeSelector.Matches(labels.Set(pvc.Labels)) {
+				continue
+			}
+			members[pvc.Name] = pvc
+		}
+	}
+
+	// Add PVCs used by the workload, except for the excluded ones.
+	// PVCs that don't exist yet, like the ones for StatefulSet replicas still being created, are skipped.
+	if vg.Spec.WorkloadRef != nil {
+		pvcNames, err := workloadPVCNames(ctx, c, vg.Namespace, vg.Spec.WorkloadRef)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, pvcName := range pvcNames {
+			if _, ok := members[pvcName]; ok {
+				continue
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := c.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: vg.Namespace}, pvc); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, nil, err
+			}
+
+			if excludeSelector.Matches(labels.Set(pvc.Labels)) {
+				continue
+			}
+			members[pvc.Name] = *pvc
+		}
+	}
+
+	// Add PVCs listed by name
+	for _, pvcName := range vg.Spec.PersistentVolumeClaimNames {
+		if _, ok := members[pvcName]; ok {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: vg.Namespace}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, pvcName)
+				continue
+			}
+			return nil, nil, err
+		}
+		members[pvc.Name] = *pvc
+	}
+
+	pvcs := make([]corev1.PersistentVolumeClaim, 0, len(members))
+	for _, pvc := range members {
+		pvcs = append(pvcs, pvc)
+	}
+
+	sort.Slice(pvcs, func(i, j int) bool {
+		return pvcs[i].Name < pvcs[j].Name
+	})
+	sort.Strings(missing)
+
+	return pvcs, missing, nil
+}
+
+// volumeGroupsForPVC maps a PersistentVolumeClaim to the VolumeGroups in its namespace
+func (r *VolumeGroupReconciler) volumeGroupsForPVC(obj client.Object) []reconcile.Request {
+	vgList := &volumegroupv1alpha1.VolumeGroupList{}
+	if err := r.List(context.Background(), vgList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, vg := range vgList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vg.Name, Namespace: vg.Namespace},
+		})
+	}
+
+	return requests
+}
+
+// volumeGroupsForWorkload returns a function that maps a workload of the kind to the VolumeGroups referring to it
+func (r *VolumeGroupReconciler) volumeGroupsForWorkload(kind string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		vgList := &volumegroupv1alpha1.VolumeGroupList{}
+		if err := r.List(context.Background(), vgList, client.InNamespace(obj.GetNamespace())); err != nil {
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, vg := range vgList.Items {
+			ref := vg.Spec.WorkloadRef
+			if ref == nil || ref.Kind != kind || ref.Name != obj.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: vg.Name, Namespace: vg.Namespace},
+			})
+		}
+
+		return requests
+	}
+}
+
+// volumeGroupsForPod maps a Pod to the VolumeGroups referring to it or to the workload controlling it
+func (r *VolumeGroupReconciler) volumeGroupsForPod(obj client.Object) []reconcile.Request {
+	ctx := context.Background()
+
+	vgList := &volumegroupv1alpha1.VolumeGroupList{}
+	if err := r.List(ctx, vgList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return []reconcile.Request{}
+	}
+
+	refs := podWorkloadRefs(ctx, r.Client, obj)
+
+	requests := []reconcile.Request{}
+	for _, vg := range vgList.Items {
+		if vg.Spec.WorkloadRef == nil {
+			continue
+		}
+		for _, ref := range refs {
+			if vg.Spec.WorkloadRef.Kind == ref.Kind && vg.Spec.WorkloadRef.Name == ref.Name {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: vg.Name, Namespace: vg.Namespace},
+				})
+				break
+			}
+		}
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VolumeGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&volumegroupv1alpha1.VolumeGroup{}).
+		Watches(&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupsForPVC)).
+		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupsForWorkload(volumegroupv1alpha1.WorkloadKindStatefulSet))).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupsForWorkload(volumegroupv1alpha1.WorkloadKindDeployment))).
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupsForPod)).
+		Complete(r)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestPVC returns a PersistentVolumeClaim with the labels
+func newTestPVC(name string, pvcLabels map[string]string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: pvcLabels}}
+}
+
+func TestVolumeGroupMembers(t *testing.T) {
+	objs := []client.Object{
+		newTestPVC("db-0", map[string]string{"app": "db"}),
+		newTestPVC("db-1", map[string]string{"app": "db"}),
+		newTestPVC("db-cache", map[string]string{"app": "db", "scratch": "true"}),
+		newTestPVC("web-0", map[string]string{"app": "web"}),
+		newTestPVC("other", nil),
+		newTestPVC("sts-pvc", nil),
+		newTestPVC("deploy-pvc", map[string]string{"scratch": "true"}),
+	}
+	c := newFakeClient(t, append(objs, newTestWorkloads()...)...)
+	exclude := &metav1.LabelSelector{MatchLabels: map[string]string{"scratch": "true"}}
+
+	tests := []struct {
+		name        string
+		spec        volumegroupv1alpha1.VolumeGroupSpec
+		wantMembers []string
+		wantMissing []string
+	}{
+		{
+			name:        "no selector",
+			wantMembers: []string{},
+			wantMissing: []string{},
+		},
+		{
+			name:        "selector",
+			spec:        volumegroupv1alpha1.VolumeGroupSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			wantMembers: []string{"db-0", "db-1", "db-cache"},
+			wantMissing: []string{},
+		},
+		{
+			name: "excluded from selector",
+			spec: volumegroupv1alpha1.VolumeGroupSpec{
+				Selector:        &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				ExcludeSelector: exclude,
+			},
+			wantMembers: []string{"db-0", "db-1"},
+			wantMissing: []string{},
+		},
+		{
+			name: "excluded from workload",
+			spec: volumegroupv1alpha1.VolumeGroupSpec{
+				WorkloadRef:     &volumegroupv1alpha1.WorkloadReference{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "deploy"},
+				ExcludeSelector: exclude,
+			},
+			wantMembers: []string{},
+			wantMissing: []string{},
+		},
+		{
+			name: "union of selector, workload and names with exclusion",
+			spec: volumegroupv1alpha1.VolumeGroupSpec{
+				Selector:                   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				ExcludeSelector:            exclude,
+				WorkloadRef:                &volumegroupv1alpha1.WorkloadReference{Kind: volumegroupv1alpha1.WorkloadKindStatefulSet, Name: "sts"},
+				PersistentVolumeClaimNames: []string{"db-0", "web-0", "missing"},
+			},
+			wantMembers: []string{"db-0", "db-1", "sts-pvc", "web-0"},
+			wantMissing: []string{"missing"},
+		},
+		{
+			name:        "names",
+			spec:        volumegroupv1alpha1.VolumeGroupSpec{PersistentVolumeClaimNames: []string{"other", "web-0"}},
+			wantMembers: []string{"other", "web-0"},
+			wantMissing: []string{},
+		},
+		{
+			name: "union of selector and names without duplicates",
+			spec: volumegroupv1alpha1.VolumeGroupSpec{
+				Selector:                   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				PersistentVolumeClaimNames: []string{"db-1", "other"},
+			},
+			wantMembers: []string{"db-0", "db-1", "db-cache", "other"},
+			wantMissing: []string{},
+		},
+		{
+			name: "missing names",
+			spec: volumegroupv1alpha1.VolumeGroupSpec{
+				Selector:                   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				PersistentVolumeClaimNames: []string{"missing-b", "other", "missing-a"},
+			},
+			wantMembers: []string{"db-0", "db-1", "db-cache", "other"},
+			wantMissing: []string{"missing-a", "missing-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vg := &volumegroupv1alpha1.VolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "vg", Namespace: "ns"}, Spec: tt.spec}
+			pvcs, missing, err := volumeGroupMembers(context.Background(), c, vg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			members := []string{}
+			for _, pvc := range pvcs {
+				members = append(members, pvc.Name)
+			}
+			if !reflect.DeepEqual(members, tt.wantMembers) {
+				t.Fatalf("expected members %v, got %v", tt.wantMembers, members)
+			}
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Fatalf("expected missing %v, got %v", tt.wantMissing, missing)
+			}
+		})
+	}
+}
+
+func TestReconcileVolumeGroupReportsMissingMembers(t *testing.T) {
+	vg := &volumegroupv1alpha1.VolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "vg", Namespace: "ns", Generation: 1},
+		Spec:       volumegroupv1alpha1.VolumeGroupSpec{PersistentVolumeClaimNames: []string{"pvc-1", "pvc-2"}},
+	}
+	c := newFakeClient(t, vg, newTestPVC("pvc-1", nil))
+	r := &VolumeGroupReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vg)}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("expected the missing member to be reported instead of an error, got %v", err)
+	}
+
+	getObject(t, c, vg)
+	if vg.Status.MemberCount != 1 || !reflect.DeepEqual(vg.Status.MissingPersistentVolumeClaimNames, []string{"pvc-2"}) {
+		t.Fatalf("expected pvc-1 to be a member and pvc-2 to be missing, got %+v", vg.Status)
+	}
+	resolved := meta.FindStatusCondition(vg.Status.Conditions, volumegroupv1alpha1.ConditionMembersResolved)
+	if resolved == nil || resolved.Status != metav1.ConditionFalse || resolved.Reason != volumegroupv1alpha1.ReasonMemberNotFound {
+		t.Fatalf("expected the members not to be resolved for %s, got %+v", volumegroupv1alpha1.ReasonMemberNotFound, resolved)
+	}
+
+	// Creating the PersistentVolumeClaim resolves the members
+	if err := c.Create(context.Background(), newTestPVC("pvc-2", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	getObject(t, c, vg)
+	if vg.Status.MemberCount != 2 || len(vg.Status.MissingPersistentVolumeClaimNames) != 0 ||
+		!meta.IsStatusConditionTrue(vg.Status.Conditions, volumegroupv1alpha1.ConditionMembersResolved) {
+		t.Fatalf("expected all the members to be resolved, got %+v", vg.Status)
+	}
+}
@@ -19,31 +19,48 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
 )
 
+// volumeGroupSnapshotFinalizer is the finalizer to delete the VolumeGroupSnapshotContent and its VolumeSnapshots in order
+const volumeGroupSnapshotFinalizer = "volumegroup.example.com/volumegroupsnapshot-protection"
+
 // VolumeGroupSnapshotReconciler reconciles a VolumeGroupSnapshot object
 type VolumeGroupSnapshotReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// DefaultTimeout is the timeout for VolumeGroupSnapshots that don't specify one. Zero means no timeout.
+	DefaultTimeout time.Duration
 }
 
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshots,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshots/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshots/finalizers,verbs=update
-//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroups,verbs=get
-//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotContents,verbs=get;create
-//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotContents/status,verbs=get
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents/status,verbs=get
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotcontents,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;
 
 // Reconcile is reconciliation loop for VolumeGroupSnapshot
@@ -59,23 +76,95 @@ func (r *VolumeGroupSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, err
 	}
 
+	if !vgs.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, vgs)
+	}
+
+	if !controllerutil.ContainsFinalizer(vgs, volumeGroupSnapshotFinalizer) {
+		controllerutil.AddFinalizer(vgs, volumeGroupSnapshotFinalizer)
+		if err := r.Update(ctx, vgs); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if vgs.Status.ReadyToUse != nil && *vgs.Status.ReadyToUse {
-		// Already ready to use
+		// Already ready to use, but the workloads and the post-snapshot hooks may still be resuming
+		return ctrl.Result{}, r.updateResumption(ctx, vgs)
+	}
+
+	if meta.IsStatusConditionTrue(vgs.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Already failed, but the applications are resumed even for the failed group snapshot
+		return ctrl.Result{}, r.updateResumption(ctx, vgs)
+	}
+
+	originalStatus := vgs.Status.DeepCopy()
+
+	// Once bound, the timeout is enforced by the VolumeGroupSnapshotContent, and its Failed condition is mirrored.
+	// The content created for the VolumeGroupSnapshot is given the deadline measured from the creation of the VolumeGroupSnapshot
+	bound := meta.IsStatusConditionTrue(vgs.Status.Conditions, volumegroupv1alpha1.ConditionContentBound)
+	if deadline, ok := deadlineFor(vgs, vgs.Spec.Timeout, r.DefaultTimeout); ok && !bound && !time.Now().Before(deadline) {
+		setGroupSnapshotFailed(vgs, volumegroupv1alpha1.ReasonTimeout,
+			fmt.Sprintf("VolumeGroupSnapshot did not become ready to use by %s", deadline.Format(time.RFC3339)))
+		if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Timed out group snapshot won't be retried
 		return ctrl.Result{}, nil
 	}
 
 	if vgs.Spec.BoundVolumeGroupSnapshotContentName == nil {
+		if vgs.Spec.ClusterVolumeGroupSnapshotContentName != nil {
+			// ClusterVolumeGroupSnapshotContent controller creates VolumeGroupSnapshotContent and binds to it
+			setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionFalse,
+				volumegroupv1alpha1.ReasonWaitingForContent, fmt.Sprintf("Waiting for ClusterVolumeGroupSnapshotContent %s to be bound", *vgs.Spec.ClusterVolumeGroupSnapshotContentName))
+			if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Binding by ClusterVolumeGroupSnapshotContent controller updates the VolumeGroupSnapshot
+			return requeueAtDeadline(vgs, vgs.Spec.Timeout, r.DefaultTimeout), nil
+		}
+
 		if vgs.Spec.VolumeGroupName != nil {
 			// Create VolumeGroupSnapshotContent for VolumeGroup
-			err := r.createVolumeGroupSnapshotContent(ctx, vgs)
+			conflict, err := r.createVolumeGroupSnapshotContent(ctx, vgs)
+			if notFoundErr, ok := asMembersNotFoundError(err); ok {
+				setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionFalse,
+					volumegroupv1alpha1.ReasonMemberNotFound, notFoundErr.Error())
+				if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+					return ctrl.Result{}, err
+				}
+
+				// Creation of the missing PersistentVolumeClaims is watched
+				return requeueAtDeadline(vgs, vgs.Spec.Timeout, r.DefaultTimeout), nil
+			}
 			if err != nil {
 				return ctrl.Result{}, err
 			}
 
-			return ctrl.Result{Requeue: true}, nil
+			if conflict != "" {
+				setGroupSnapshotFailed(vgs, volumegroupv1alpha1.ReasonContentConflict, conflict)
+				if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+					return ctrl.Result{}, err
+				}
+
+				// Conflicting VolumeGroupSnapshotContent won't be adopted, so stop retrying
+				return ctrl.Result{}, nil
+			}
+
+			// Binding updates the VolumeGroupSnapshot, which triggers the next reconciliation
+			return ctrl.Result{}, nil
+		}
+
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForContent, "Neither volumeGroupName nor boundVolumeGroupSnapshotContentName is specified")
+		if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+			return ctrl.Result{}, err
 		}
-		// Retry until BoundVolumeGroupSnapshotContentName become non-nil.
-		return ctrl.Result{Requeue: true}, nil
+
+		// Wait until BoundVolumeGroupSnapshotContentName become non-nil.
+		return requeueAtDeadline(vgs, vgs.Spec.Timeout, r.DefaultTimeout), nil
 	}
 
 	// Update ReadyToUse
@@ -84,36 +173,82 @@ func (r *VolumeGroupSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, err
 	}
 
+	if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if meta.IsStatusConditionTrue(vgs.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Failed VolumeGroupSnapshotContent won't become ready, so stop retrying
+		return ctrl.Result{}, nil
+	}
+
 	if !readyToUse {
-		return ctrl.Result{Requeue: true}, nil
+		if meta.IsStatusConditionTrue(vgs.Status.Conditions, volumegroupv1alpha1.ConditionContentBound) {
+			// Progress of the VolumeGroupSnapshotContent, including its timeout, is watched
+			return ctrl.Result{}, nil
+		}
+		return requeueAtDeadline(vgs, vgs.Spec.Timeout, r.DefaultTimeout), nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func (r *VolumeGroupSnapshotReconciler) createVolumeGroupSnapshotContent(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) error {
+// updateResumption mirrors the restoration of the workloads and the results of the hooks
+// of the bound VolumeGroupSnapshotContent until they complete
+func (r *VolumeGroupSnapshotReconciler) updateResumption(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) error {
+	if vgs.Spec.BoundVolumeGroupSnapshotContentName == nil ||
+		(workloadsRestored(vgs.Spec.Quiesce, vgs.Status.Conditions) && postSnapshotHooksCompleted(vgs.Spec.Hooks, vgs.Status.Hooks)) {
+		return nil
+	}
+
+	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+	if err := r.Get(ctx, types.NamespacedName{Name: *vgs.Spec.BoundVolumeGroupSnapshotContentName, Namespace: vgs.Namespace}, vgsc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if contentBindingError(vgs, vgsc) != "" {
+		return nil
+	}
+
+	originalStatus := vgs.Status.DeepCopy()
+	mirrorQuiesced(vgs, vgsc)
+	mirrorHooks(vgs, vgsc)
+	return r.updateStatus(ctx, vgs, originalStatus)
+}
+
+// createVolumeGroupSnapshotContent creates the VolumeGroupSnapshotContent for the VolumeGroup and binds to it.
+// It returns the reason if a VolumeGroupSnapshotContent with the same name exists but wasn't created for the VolumeGroupSnapshot.
+func (r *VolumeGroupSnapshotReconciler) createVolumeGroupSnapshotContent(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (string, error) {
 	vgsc, err := r.volumeGroupSnapshotContentFor(ctx, vgs)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	err = r.Create(ctx, vgsc)
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
-			return err
+			return "", err
+		}
+
+		// Only adopt the VolumeGroupSnapshotContent created for this VolumeGroupSnapshot
+		existing := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vgsc.Name, Namespace: vgsc.Namespace}, existing); err != nil {
+			return "", err
+		}
+		if !metav1.IsControlledBy(existing, vgs) || existing.Labels[volumegroupv1alpha1.VolumeGroupSnapshotLabel] != vgs.Name ||
+			existing.Spec.VolumeGroupSnapshotUID != vgs.UID {
+			return fmt.Sprintf("VolumeGroupSnapshotContent %s already exists and is not owned by VolumeGroupSnapshot %s", existing.Name, vgs.Name), nil
 		}
-		// Continue already exists case
 	}
 
 	// Set vgsc.Name to vgs's VolumeGroupSnapshotContentName
 	vgs.Spec.BoundVolumeGroupSnapshotContentName = &vgsc.Name
-	// TODO: Consider also setting CreationTime somewhere
 
 	if err := r.Update(ctx, vgs); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return "", nil
 }
 
 func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotContentFor(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (*volumegroupv1alpha1.VolumeGroupSnapshotContent, error) {
@@ -127,16 +262,14 @@ func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotContentFor(ctx contex
 		return nil, err
 	}
 
-	pvcList := &corev1.PersistentVolumeClaimList{}
-	selector, err := metav1.LabelSelectorAsSelector(vg.Spec.Selector)
+	pvcs, missing, err := volumeGroupMembers(ctx, r.Client, vg)
 	if err != nil {
 		return nil, err
 	}
-	//listOpts := []client.ListOption{labels.Set(selector.String()).String()}
-	listOpts := &client.ListOptions{LabelSelector: selector}
 
-	if err := r.List(ctx, pvcList, listOpts); err != nil {
-		return nil, err
+	// Group snapshot of the partial members isn't taken
+	if len(missing) > 0 {
+		return nil, &membersNotFoundError{volumeGroup: vg.Name, names: missing}
 	}
 
 	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{
@@ -144,16 +277,38 @@ func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotContentFor(ctx contex
 			// TODO: Consider generating a better name for VolumeGroupSnapshotContent from vgs.Name
 			Name:      fmt.Sprintf("vgsc-%s", vgs.Name),
 			Namespace: vgs.Namespace,
+			Labels: map[string]string{
+				volumegroupv1alpha1.VolumeGroupSnapshotLabel: vgs.Name,
+			},
 		},
 		Spec: volumegroupv1alpha1.VolumeGroupSnapshotContentSpec{
 			VolumeGroupSnapshotName:   &vgs.Name,
+			VolumeGroupSnapshotUID:    vgs.UID,
 			PersistentVolumeClaimList: []string{},
 			SnapshotList:              []string{},
+			VolumeSnapshotClassName:   vgs.Spec.VolumeSnapshotClassName,
+			Timeout:                   vgs.Spec.Timeout,
+			FailurePolicy:             vgs.Spec.FailurePolicy,
+			IssuanceMode:              vgs.Spec.IssuanceMode,
+			MaxSkew:                   vgs.Spec.MaxSkew,
+			Hooks:                     vgs.Spec.Hooks,
+			Quiesce:                   vgs.Spec.Quiesce,
+			Workloads:                 vgs.Spec.Workloads,
+			DeletionPolicy:            vgs.Spec.DeletionPolicy,
 		},
 	}
 
+	if vgsc.Spec.DeletionPolicy == "" {
+		vgsc.Spec.DeletionPolicy = volumegroupv1alpha1.DeletionPolicyDelete
+	}
+
+	// The time spent before the creation of the content counts toward the timeout
+	if deadline, ok := deadlineFor(vgs, vgs.Spec.Timeout, r.DefaultTimeout); ok {
+		vgsc.Spec.Deadline = &metav1.Time{Time: deadline}
+	}
+
 	// Set all PVC's names to PersistentVolumeClaimList
-	for _, pvc := range pvcList.Items {
+	for _, pvc := range pvcs {
 		vgsc.Spec.PersistentVolumeClaimList = append(vgsc.Spec.PersistentVolumeClaimList, pvc.Name)
 	}
 
@@ -171,27 +326,367 @@ func (r *VolumeGroupSnapshotReconciler) updateReadyToUse(ctx context.Context, vg
 	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
 
 	if err := r.Get(ctx, types.NamespacedName{Name: *vgs.Spec.BoundVolumeGroupSnapshotContentName, Namespace: vgs.Namespace}, vgsc); err != nil {
-		return false, err
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonContentNotFound, fmt.Sprintf("VolumeGroupSnapshotContent %s is not found", *vgs.Spec.BoundVolumeGroupSnapshotContentName))
+		return false, nil
+	}
+
+	// Verify that the VolumeGroupSnapshotContent refers back to this VolumeGroupSnapshot
+	if message := contentBindingError(vgs, vgsc); message != "" {
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonBindingMismatch, message)
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonBindingMismatch, message)
+		return false, nil
+	}
+
+	if vgsc.Spec.VolumeGroupSnapshotUID == "" {
+		// Claim the pre-provisioned VolumeGroupSnapshotContent, which fails on conflict with another claim
+		vgsc.Spec.VolumeGroupSnapshotUID = vgs.UID
+		if err := r.Update(ctx, vgsc); err != nil {
+			return false, err
+		}
+	}
+
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionContentBound, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonBound, fmt.Sprintf("Bound to VolumeGroupSnapshotContent %s", vgsc.Name))
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionFalse,
+		volumegroupv1alpha1.ReasonNoFailure, "")
+
+	// Creation time is decided by the VolumeGroupSnapshotContent
+	vgs.Status.CreationTime = vgsc.Status.CreationTime.DeepCopy()
+	vgs.Status.LatestCreationTime = vgsc.Status.LatestCreationTime.DeepCopy()
+	vgs.Status.CreationTimeSkew = vgsc.Status.CreationTimeSkew.DeepCopy()
+	vgs.Status.IssuanceSkew = vgsc.Status.IssuanceSkew.DeepCopy()
+
+	// Mirror CrashConsistent of the VolumeGroupSnapshotContent
+	if crashConsistent := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionCrashConsistent); crashConsistent != nil {
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionCrashConsistent, crashConsistent.Status,
+			crashConsistent.Reason, crashConsistent.Message)
+	}
+
+	// Mirror the scale down of the workloads and the results of the hooks run by the VolumeGroupSnapshotContent
+	mirrorQuiesced(vgs, vgsc)
+	mirrorHooks(vgs, vgsc)
+
+	// Mirror SnapshotsCreated of the VolumeGroupSnapshotContent
+	if created := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionSnapshotsCreated); created != nil {
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, created.Status,
+			created.Reason, created.Message)
+	}
+
+	// Propagate the failure of the VolumeGroupSnapshotContent
+	if failed := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed); failed != nil && failed.Status == metav1.ConditionTrue {
+		if partial := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionPartial); partial != nil {
+			setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionPartial, partial.Status,
+				partial.Reason, partial.Message)
+		}
+
+		vgs.Status.Error = vgsc.Status.Error.DeepCopy()
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+			failed.Reason, failed.Message)
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			failed.Reason, fmt.Sprintf("VolumeGroupSnapshotContent %s has failed", vgsc.Name))
+		return false, nil
 	}
 
 	if vgsc.Status.ReadyToUse == nil || !*vgsc.Status.ReadyToUse {
 		// VolumeGroupSnapshotContent for this VolumeGroupSnapshot isn't ready to use yet
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForContent, fmt.Sprintf("VolumeGroupSnapshotContent %s is not ready to use", vgsc.Name))
 		return false, nil
 	}
 
 	// Update VolumeGroupSnapshot's ReadyToUse to true
 	vgs.Status.ReadyToUse = vgsc.Status.ReadyToUse
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonReady, fmt.Sprintf("VolumeGroupSnapshotContent %s is ready to use", vgsc.Name))
+
+	return true, nil
+}
+
+// setGroupSnapshotFailed marks the VolumeGroupSnapshot as failed for the reason
+func setGroupSnapshotFailed(vgs *volumegroupv1alpha1.VolumeGroupSnapshot, reason, message string) {
+	vgs.Status.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{Time: &metav1.Time{Time: time.Now()}, Message: &message}
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+		reason, message)
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+		reason, message)
+}
+
+// finalize deletes the VolumeSnapshots, waits for their VolumeSnapshotContents to be released, and
+// deletes the VolumeGroupSnapshotContent in this order, then removes the finalizer
+func (r *VolumeGroupSnapshotReconciler) finalize(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(vgs, volumeGroupSnapshotFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	originalStatus := vgs.Status.DeepCopy()
+
+	deleted, err := r.deleteVolumeGroupSnapshotContent(ctx, vgs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, vgs, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !deleted {
+		// Deletion of the VolumeSnapshots, VolumeSnapshotContents and VolumeGroupSnapshotContent is watched
+		return ctrl.Result{}, nil
+	}
+
+	controllerutil.RemoveFinalizer(vgs, volumeGroupSnapshotFinalizer)
+	if err := r.Update(ctx, vgs); err != nil {
+		return ctrl.Result{}, err
+	}
 
-	if err := r.Status().Update(ctx, vgs); err != nil {
+	return ctrl.Result{}, nil
+}
+
+// deleteVolumeGroupSnapshotContent deletes the bound VolumeGroupSnapshotContent after its VolumeSnapshots, and
+// returns true once it is gone. A VolumeGroupSnapshotContent with the Retain policy is released instead.
+func (r *VolumeGroupSnapshotReconciler) deleteVolumeGroupSnapshotContent(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot) (bool, error) {
+	if vgs.Spec.BoundVolumeGroupSnapshotContentName == nil {
+		return true, nil
+	}
+
+	vgsc := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+	if err := r.Get(ctx, types.NamespacedName{Name: *vgs.Spec.BoundVolumeGroupSnapshotContentName, Namespace: vgs.Namespace}, vgsc); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
 		return false, err
 	}
 
-	return true, nil
+	if contentBindingError(vgs, vgsc) != "" || vgsc.Spec.VolumeGroupSnapshotUID != vgs.UID {
+		// Not bound to this VolumeGroupSnapshot, so leave it as it is
+		return true, nil
+	}
+
+	if vgsc.Spec.DeletionPolicy != volumegroupv1alpha1.DeletionPolicyDelete {
+		// Remove the owner reference so that the VolumeGroupSnapshotContent isn't garbage collected
+		ownerRefs := []metav1.OwnerReference{}
+		for _, ownerRef := range vgsc.OwnerReferences {
+			if ownerRef.UID != vgs.UID {
+				ownerRefs = append(ownerRefs, ownerRef)
+			}
+		}
+		if len(ownerRefs) == len(vgsc.OwnerReferences) {
+			return true, nil
+		}
+
+		vgsc.OwnerReferences = ownerRefs
+		if err := r.Update(ctx, vgsc); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	blocking, err := r.deleteVolumeSnapshots(ctx, vgsc)
+	if err != nil {
+		return false, err
+	}
+
+	if blocking != "" {
+		setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionDeleting, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonDeletingSnapshots, blocking)
+		return false, nil
+	}
+
+	if vgsc.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, vgsc); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	setCondition(&vgs.Status.Conditions, vgs.Generation, volumegroupv1alpha1.ConditionDeleting, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonDeletingContent, fmt.Sprintf("VolumeGroupSnapshotContent %s is being deleted", vgsc.Name))
+	return false, nil
+}
+
+// deleteVolumeSnapshots deletes the VolumeSnapshots of the VolumeGroupSnapshotContent, and returns the message
+// about the first member blocking the deletion. It returns an empty message once all the VolumeSnapshots are
+// deleted and their VolumeSnapshotContents are released.
+func (r *VolumeGroupSnapshotReconciler) deleteVolumeSnapshots(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (string, error) {
+	blocking := ""
+	for _, vsName := range vgsc.Spec.SnapshotList {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}, vs); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+
+		if vs.DeletionTimestamp.IsZero() {
+			if err := r.Delete(ctx, vs); err != nil && !errors.IsNotFound(err) {
+				return "", err
+			}
+		}
+
+		if blocking == "" {
+			blocking = fmt.Sprintf("VolumeSnapshot %s is being deleted", vs.Name)
+			if vs.Status != nil && vs.Status.Error != nil && vs.Status.Error.Message != nil {
+				blocking = fmt.Sprintf("%s: %s", blocking, *vs.Status.Error.Message)
+			}
+		}
+	}
+
+	if blocking != "" {
+		return blocking, nil
+	}
+
+	// Wait for the VolumeSnapshotContents to be deleted unless they are retained
+	for _, member := range vgsc.Status.Members {
+		if member.VolumeSnapshotContentName == nil {
+			continue
+		}
+
+		vsc := &snapshotv1.VolumeSnapshotContent{}
+		if err := r.Get(ctx, types.NamespacedName{Name: *member.VolumeSnapshotContentName}, vsc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+
+		if vsc.Spec.DeletionPolicy == snapshotv1.VolumeSnapshotContentRetain {
+			// Released from the deleted VolumeSnapshot
+			continue
+		}
+
+		blocking = fmt.Sprintf("VolumeSnapshotContent %s of VolumeSnapshot %s is being deleted", vsc.Name, member.VolumeSnapshotName)
+		if vsc.Status != nil && vsc.Status.Error != nil && vsc.Status.Error.Message != nil {
+			blocking = fmt.Sprintf("%s: %s", blocking, *vsc.Status.Error.Message)
+		}
+		return blocking, nil
+	}
+
+	return "", nil
+}
+
+// contentBindingError returns the reason why the VolumeGroupSnapshotContent can't be bound to the VolumeGroupSnapshot,
+// or an empty string if it refers back to the VolumeGroupSnapshot
+func contentBindingError(vgs *volumegroupv1alpha1.VolumeGroupSnapshot, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) string {
+	if vgsc.Spec.VolumeGroupSnapshotName == nil || *vgsc.Spec.VolumeGroupSnapshotName != vgs.Name {
+		return fmt.Sprintf("VolumeGroupSnapshotContent %s does not refer to VolumeGroupSnapshot %s", vgsc.Name, vgs.Name)
+	}
+
+	if vgsc.Spec.VolumeGroupSnapshotUID != "" && vgsc.Spec.VolumeGroupSnapshotUID != vgs.UID {
+		return fmt.Sprintf("VolumeGroupSnapshotContent %s is bound to another VolumeGroupSnapshot with UID %s", vgsc.Name, vgsc.Spec.VolumeGroupSnapshotUID)
+	}
+
+	return ""
+}
+
+// updateStatus updates the status of the VolumeGroupSnapshot only if it differs from the original
+func (r *VolumeGroupSnapshotReconciler) updateStatus(ctx context.Context, vgs *volumegroupv1alpha1.VolumeGroupSnapshot, original *volumegroupv1alpha1.VolumeGroupSnapshotStatus) error {
+	vgs.Status.ObservedGeneration = vgs.Generation
+
+	if equality.Semantic.DeepEqual(original, &vgs.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, vgs)
+}
+
+// volumeGroupSnapshotsForContent maps a VolumeGroupSnapshotContent to the VolumeGroupSnapshots bound to it
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForContent(obj client.Object) []reconcile.Request {
+	return r.volumeGroupSnapshotsMatchingFields(obj.GetNamespace(), client.MatchingFields{boundContentNameIndex: obj.GetName()})
+}
+
+// volumeGroupSnapshotsForVolumeGroup maps a VolumeGroup to the VolumeGroupSnapshots taken from it
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForVolumeGroup(obj client.Object) []reconcile.Request {
+	return r.volumeGroupSnapshotsMatchingFields(obj.GetNamespace(), client.MatchingFields{volumeGroupNameIndex: obj.GetName()})
+}
+
+// volumeGroupSnapshotsForPVC maps a PersistentVolumeClaim to the VolumeGroupSnapshots in its namespace
+// that have yet to decide their members
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForPVC(obj client.Object) []reconcile.Request {
+	vgsList := &volumegroupv1alpha1.VolumeGroupSnapshotList{}
+	if err := r.List(context.Background(), vgsList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, vgs := range vgsList.Items {
+		if vgs.Spec.VolumeGroupName == nil || vgs.Spec.BoundVolumeGroupSnapshotContentName != nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vgs.Name, Namespace: vgs.Namespace},
+		})
+	}
+
+	return requests
+}
+
+// volumeGroupSnapshotsForVolumeSnapshot maps a VolumeSnapshot to the VolumeGroupSnapshots whose
+// VolumeGroupSnapshotContent lists it
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForVolumeSnapshot(obj client.Object) []reconcile.Request {
+	return r.volumeGroupSnapshotsForSnapshotName(obj.GetNamespace(), obj.GetName())
+}
+
+// volumeGroupSnapshotsForVolumeSnapshotContent maps a VolumeSnapshotContent to the VolumeGroupSnapshots whose
+// VolumeGroupSnapshotContent lists the VolumeSnapshot bound to it, so that deletion can wait for its release
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForVolumeSnapshotContent(obj client.Object) []reconcile.Request {
+	vsc, ok := obj.(*snapshotv1.VolumeSnapshotContent)
+	if !ok || vsc.Spec.VolumeSnapshotRef.Name == "" {
+		return []reconcile.Request{}
+	}
+
+	return r.volumeGroupSnapshotsForSnapshotName(vsc.Spec.VolumeSnapshotRef.Namespace, vsc.Spec.VolumeSnapshotRef.Name)
+}
+
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsForSnapshotName(namespace, vsName string) []reconcile.Request {
+	vgscs, err := volumeGroupSnapshotContentsFor(context.Background(), r.Client, namespace, vsName)
+	if err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, vgsc := range vgscs {
+		requests = append(requests, r.volumeGroupSnapshotsMatchingFields(namespace, client.MatchingFields{boundContentNameIndex: vgsc.Name})...)
+	}
+
+	return requests
+}
+
+func (r *VolumeGroupSnapshotReconciler) volumeGroupSnapshotsMatchingFields(namespace string, fields client.MatchingFields) []reconcile.Request {
+	vgsList := &volumegroupv1alpha1.VolumeGroupSnapshotList{}
+	if err := r.List(context.Background(), vgsList, client.InNamespace(namespace), fields); err != nil {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, vgs := range vgsList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vgs.Name, Namespace: vgs.Namespace},
+		})
+	}
+
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VolumeGroupSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&volumegroupv1alpha1.VolumeGroupSnapshot{}).
+		Watches(&source.Kind{Type: &volumegroupv1alpha1.VolumeGroupSnapshotContent{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotsForContent)).
+		Watches(&source.Kind{Type: &volumegroupv1alpha1.VolumeGroup{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotsForVolumeGroup)).
+		Watches(&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotsForPVC)).
+		Watches(&source.Kind{Type: &snapshotv1.VolumeSnapshot{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotsForVolumeSnapshot)).
+		Watches(&source.Kind{Type: &snapshotv1.VolumeSnapshotContent{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotsForVolumeSnapshotContent)).
 		Complete(r)
 }
@@ -0,0 +1,331 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+func TestVolumeGroupSnapshotContentForTakesDeadlineOfGroupSnapshot(t *testing.T) {
+	vgName := "vg"
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "vgs", Namespace: "ns", UID: "vgs-uid", CreationTimestamp: metav1.Time{Time: created}},
+		Spec: volumegroupv1alpha1.VolumeGroupSnapshotSpec{
+			VolumeGroupName: &vgName,
+			Timeout:         &metav1.Duration{Duration: 90 * time.Minute},
+		},
+	}
+	vg := &volumegroupv1alpha1.VolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: vgName, Namespace: "ns"},
+		Spec:       volumegroupv1alpha1.VolumeGroupSpec{PersistentVolumeClaimNames: []string{"pvc-1"}},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1", Namespace: "ns"}}
+
+	c := newFakeClient(t, vg, pvc)
+	r := &VolumeGroupSnapshotReconciler{Client: c, Scheme: c.Scheme(), DefaultTimeout: 10 * time.Minute}
+
+	vgsc, err := r.volumeGroupSnapshotContentFor(context.Background(), vgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := created.Add(90 * time.Minute); vgsc.Spec.Deadline == nil || !vgsc.Spec.Deadline.Time.Equal(want) {
+		t.Fatalf("expected the deadline %s, got %v", want, vgsc.Spec.Deadline)
+	}
+
+	// The default timeout is measured from the creation of the VolumeGroupSnapshot as well
+	vgs.Spec.Timeout = nil
+	if vgsc, err = r.volumeGroupSnapshotContentFor(context.Background(), vgs); err != nil {
+		t.Fatal(err)
+	}
+	if want := created.Add(10 * time.Minute); vgsc.Spec.Deadline == nil || !vgsc.Spec.Deadline.Time.Equal(want) {
+		t.Fatalf("expected the deadline %s, got %v", want, vgsc.Spec.Deadline)
+	}
+
+	r.DefaultTimeout = 0
+	if vgsc, err = r.volumeGroupSnapshotContentFor(context.Background(), vgs); err != nil {
+		t.Fatal(err)
+	}
+	if vgsc.Spec.Deadline != nil {
+		t.Fatalf("expected no deadline without timeout, got %v", vgsc.Spec.Deadline)
+	}
+}
+
+// newTestBoundGroupSnapshot returns a VolumeGroupSnapshot protected by the finalizer and bound to the VolumeGroupSnapshotContent,
+// which refers back to it and is controlled by it
+func newTestBoundGroupSnapshot(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) *volumegroupv1alpha1.VolumeGroupSnapshot {
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "vgs",
+			Namespace:  "ns",
+			UID:        "vgs-uid",
+			Generation: 1,
+			Finalizers: []string{volumeGroupSnapshotFinalizer},
+		},
+		Spec: volumegroupv1alpha1.VolumeGroupSnapshotSpec{BoundVolumeGroupSnapshotContentName: &vgsc.Name},
+	}
+
+	controller := true
+	vgsc.Spec.VolumeGroupSnapshotName = &vgs.Name
+	vgsc.Spec.VolumeGroupSnapshotUID = vgs.UID
+	vgsc.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: volumegroupv1alpha1.GroupVersion.String(),
+		Kind:       "VolumeGroupSnapshot",
+		Name:       vgs.Name,
+		UID:        vgs.UID,
+		Controller: &controller,
+	}}
+
+	return vgs
+}
+
+func TestVolumeGroupSnapshotContentForTakesDeletionPolicy(t *testing.T) {
+	vgName := "vg"
+	vg := &volumegroupv1alpha1.VolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: vgName, Namespace: "ns"},
+		Spec:       volumegroupv1alpha1.VolumeGroupSpec{PersistentVolumeClaimNames: []string{"pvc-1"}},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1", Namespace: "ns"}}
+	c := newFakeClient(t, vg, pvc)
+	r := &VolumeGroupSnapshotReconciler{Client: c, Scheme: c.Scheme()}
+
+	tests := []struct {
+		name   string
+		policy volumegroupv1alpha1.DeletionPolicy
+		want   volumegroupv1alpha1.DeletionPolicy
+	}{
+		{name: "default", want: volumegroupv1alpha1.DeletionPolicyDelete},
+		{name: "delete", policy: volumegroupv1alpha1.DeletionPolicyDelete, want: volumegroupv1alpha1.DeletionPolicyDelete},
+		{name: "retain", policy: volumegroupv1alpha1.DeletionPolicyRetain, want: volumegroupv1alpha1.DeletionPolicyRetain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: "vgs", Namespace: "ns", UID: "vgs-uid"},
+				Spec:       volumegroupv1alpha1.VolumeGroupSnapshotSpec{VolumeGroupName: &vgName, DeletionPolicy: tt.policy},
+			}
+			vgsc, err := r.volumeGroupSnapshotContentFor(context.Background(), vgs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vgsc.Spec.DeletionPolicy != tt.want {
+				t.Fatalf("expected the DeletionPolicy %s, got %s", tt.want, vgsc.Spec.DeletionPolicy)
+			}
+		})
+	}
+}
+
+func TestFinalizeGroupSnapshotReleasesRetainedContent(t *testing.T) {
+	vgsc := newTestContent("pvc-1")
+	vgsc.Spec.DeletionPolicy = volumegroupv1alpha1.DeletionPolicyRetain
+	vgsc.Spec.SnapshotList = []string{"vs-1"}
+	vgs := newTestBoundGroupSnapshot(vgsc)
+	now := metav1.Now()
+	vgs.DeletionTimestamp = &now
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+
+	c := newFakeClient(t, vgs, vgsc, vs)
+	r := &VolumeGroupSnapshotReconciler{Client: c}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgs)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The content and its VolumeSnapshots outlive the VolumeGroupSnapshot
+	getObject(t, c, vgsc)
+	if len(vgsc.OwnerReferences) != 0 {
+		t.Fatalf("expected the retained content to be released, got %v", vgsc.OwnerReferences)
+	}
+	getObject(t, c, vs)
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(vgs), vgs); !errors.IsNotFound(err) {
+		t.Fatalf("expected the finalizer to be removed, got %v %v", vgs.Finalizers, err)
+	}
+}
+
+// removeFinalizers removes the finalizers of the object being deleted, as its controller would, so that it is gone
+func removeFinalizers(t *testing.T, c client.Client, obj client.Object) {
+	getObject(t, c, obj)
+	obj.SetFinalizers(nil)
+	if err := c.Update(context.Background(), obj); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFinalizeGroupSnapshotDeletesInOrder(t *testing.T) {
+	vgsc := newTestContent("pvc-1")
+	vgsc.Spec.DeletionPolicy = volumegroupv1alpha1.DeletionPolicyDelete
+	vgsc.Spec.SnapshotList = []string{"vs-1"}
+	vscName := "vsc-1"
+	vgsc.Status.Members = []volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus{{VolumeSnapshotName: "vs-1", VolumeSnapshotContentName: &vscName}}
+	vgs := newTestBoundGroupSnapshot(vgsc)
+	now := metav1.Now()
+	vgs.DeletionTimestamp = &now
+
+	// The snapshot controller protects the bound VolumeSnapshot, and the backend fails to delete the snapshot
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+	vs.Finalizers = []string{"snapshot.storage.kubernetes.io/volumesnapshot-bound-protection"}
+	message := "backend refused"
+	vsc := &snapshotv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: vscName},
+		Spec:       snapshotv1.VolumeSnapshotContentSpec{DeletionPolicy: snapshotv1.VolumeSnapshotContentDelete},
+		Status:     &snapshotv1.VolumeSnapshotContentStatus{Error: &snapshotv1.VolumeSnapshotError{Message: &message}},
+	}
+
+	c := newFakeClient(t, vgs, vgsc, vs, vsc)
+	r := &VolumeGroupSnapshotReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgs)}
+	ctx := context.Background()
+
+	expectDeleting := func(reason, message string) {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+		getObject(t, c, vgs)
+		deleting := meta.FindStatusCondition(vgs.Status.Conditions, volumegroupv1alpha1.ConditionDeleting)
+		if deleting == nil || deleting.Reason != reason || !strings.Contains(deleting.Message, message) {
+			t.Fatalf("expected the deletion to be reported for %s with %q, got %+v", reason, message, deleting)
+		}
+		if len(vgs.Finalizers) == 0 {
+			t.Fatal("expected the finalizer to be kept")
+		}
+	}
+
+	// The VolumeSnapshots are deleted first
+	expectDeleting(volumegroupv1alpha1.ReasonDeletingSnapshots, "VolumeSnapshot vs-1 is being deleted")
+	getObject(t, c, vs)
+	if vs.DeletionTimestamp.IsZero() {
+		t.Fatal("expected the VolumeSnapshot to be deleted")
+	}
+	getObject(t, c, vgsc)
+	if !vgsc.DeletionTimestamp.IsZero() {
+		t.Fatal("expected the content to be kept until its VolumeSnapshots are deleted")
+	}
+
+	// Then their VolumeSnapshotContents are waited for, reporting the blocking error
+	removeFinalizers(t, c, vs)
+	expectDeleting(volumegroupv1alpha1.ReasonDeletingSnapshots, message)
+	getObject(t, c, vgsc)
+	if !vgsc.DeletionTimestamp.IsZero() {
+		t.Fatal("expected the content to be kept until the VolumeSnapshotContents are released")
+	}
+
+	// Then the content is deleted
+	if err := c.Delete(ctx, vsc); err != nil {
+		t.Fatal(err)
+	}
+	expectDeleting(volumegroupv1alpha1.ReasonDeletingContent, "VolumeGroupSnapshotContent vgsc is being deleted")
+	getObject(t, c, vgsc)
+	if vgsc.DeletionTimestamp.IsZero() {
+		t.Fatal("expected the content to be deleted")
+	}
+
+	// Finally the VolumeGroupSnapshot goes once the content is gone
+	removeFinalizers(t, c, vgsc)
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vgs), vgs); !errors.IsNotFound(err) {
+		t.Fatalf("expected the finalizer to be removed, got %v %v", vgs.Finalizers, err)
+	}
+}
+
+func TestContentBindingError(t *testing.T) {
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "vgs", Namespace: "ns", UID: "vgs-uid"}}
+	vgsName, otherName := "vgs", "other"
+
+	tests := []struct {
+		name      string
+		refName   *string
+		refUID    types.UID
+		wantError bool
+	}{
+		{name: "bound by name", refName: &vgsName},
+		{name: "bound by name and UID", refName: &vgsName, refUID: vgs.UID},
+		{name: "no back-reference", wantError: true},
+		{name: "other name", refName: &otherName, wantError: true},
+		{name: "other UID", refName: &vgsName, refUID: "other-uid", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vgsc := newTestContent()
+			vgsc.Spec.VolumeGroupSnapshotName = tt.refName
+			vgsc.Spec.VolumeGroupSnapshotUID = tt.refUID
+
+			if message := contentBindingError(vgs, vgsc); (message != "") != tt.wantError {
+				t.Fatalf("expected the binding error %t, got %q", tt.wantError, message)
+			}
+		})
+	}
+}
+
+func TestUpdateReadyToUseClaimsPreProvisionedContent(t *testing.T) {
+	// The pre-provisioned content refers to the VolumeGroupSnapshot by name, but isn't claimed yet
+	vgsName := "vgs"
+	vgsc := newTestContent()
+	vgsc.Spec.VolumeGroupSnapshotName = &vgsName
+	ready := true
+	vgsc.Status.ReadyToUse = &ready
+	vgs := &volumegroupv1alpha1.VolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: vgsName, Namespace: "ns", UID: "vgs-uid", Generation: 1},
+		Spec:       volumegroupv1alpha1.VolumeGroupSnapshotSpec{BoundVolumeGroupSnapshotContentName: &vgsc.Name},
+	}
+
+	c := newFakeClient(t, vgs, vgsc)
+	r := &VolumeGroupSnapshotReconciler{Client: c}
+	readyToUse, err := r.updateReadyToUse(context.Background(), vgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !readyToUse {
+		t.Fatalf("expected the bound group snapshot to be ready to use, got %+v", vgs.Status.Conditions)
+	}
+	getObject(t, c, vgsc)
+	if vgsc.Spec.VolumeGroupSnapshotUID != vgs.UID {
+		t.Fatalf("expected the content to be claimed by UID %s, got %q", vgs.UID, vgsc.Spec.VolumeGroupSnapshotUID)
+	}
+
+	// A VolumeGroupSnapshot recreated with the same name can't take over the claimed content
+	recreated := vgs.DeepCopy()
+	recreated.UID = "recreated-uid"
+	recreated.Status = volumegroupv1alpha1.VolumeGroupSnapshotStatus{}
+	if readyToUse, err = r.updateReadyToUse(context.Background(), recreated); err != nil {
+		t.Fatal(err)
+	}
+	if readyToUse {
+		t.Fatal("expected the content claimed by another VolumeGroupSnapshot not to be bound")
+	}
+	bound := meta.FindStatusCondition(recreated.Status.Conditions, volumegroupv1alpha1.ConditionContentBound)
+	if bound == nil || bound.Status != metav1.ConditionFalse || bound.Reason != volumegroupv1alpha1.ReasonBindingMismatch {
+		t.Fatalf("expected the binding mismatch to be reported, got %+v", bound)
+	}
+}
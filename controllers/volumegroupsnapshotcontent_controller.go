@@ -19,29 +19,70 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
 )
 
+// volumeGroupSnapshotContentFinalizer is the finalizer to enforce the DeletionPolicy of VolumeGroupSnapshotContent
+const volumeGroupSnapshotContentFinalizer = "volumegroup.example.com/volumegroupsnapshotcontent-protection"
+
+// defaultMaxConcurrentSnapshotCreations is used when MaxConcurrentSnapshotCreations isn't set
+const defaultMaxConcurrentSnapshotCreations = 10
+
 // VolumeGroupSnapshotContentReconciler reconciles a VolumeGroupSnapshotContent object
 type VolumeGroupSnapshotContentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// DefaultTimeout is the timeout for VolumeGroupSnapshotContents that don't specify one. Zero means no timeout.
+	DefaultTimeout time.Duration
+
+	// MaxConcurrentSnapshotCreations is the maximum number of VolumeSnapshots created at the same time for a group
+	MaxConcurrentSnapshotCreations int
+
+	// HTTPClient is used to call the HTTP hooks. A client that doesn't follow redirects nor connect to
+	// the loopback and link-local addresses is used if nil.
+	HTTPClient *http.Client
+
+	// APIReader reads the objects bypassing the cache, to confirm that the VolumeSnapshots missing
+	// from the cache are deleted. The client is used if nil.
+	APIReader client.Reader
 }
 
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=volumegroup.example.com,resources=volumegroupsnapshotcontents/finalizers,verbs=update
-//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;create
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotcontents,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
 
 // Reconcile is reconciliation loop for VolumeGroupSnapshotContent
 func (r *VolumeGroupSnapshotContentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -56,148 +97,905 @@ func (r *VolumeGroupSnapshotContentReconciler) Reconcile(ctx context.Context, re
 		return ctrl.Result{}, err
 	}
 
+	if !vgsc.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, vgsc)
+	}
+
+	if !controllerutil.ContainsFinalizer(vgsc, volumeGroupSnapshotContentFinalizer) {
+		controllerutil.AddFinalizer(vgsc, volumeGroupSnapshotContentFinalizer)
+		if err := r.Update(ctx, vgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if vgsc.Status.ReadyToUse != nil && *vgsc.Status.ReadyToUse {
-		// Already ready to use
+		// Already ready to use, but the workloads and the post-snapshot hooks may still be resuming
+		return r.resume(ctx, vgsc)
+	}
+
+	if meta.IsStatusConditionTrue(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// Already failed, but the partial set may not be handled yet
+		if err := r.handlePartialSnapshots(ctx, vgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// The workloads and the applications are resumed even for the failed group snapshot
+		return r.resume(ctx, vgsc)
+	}
+
+	originalStatus := vgsc.Status.DeepCopy()
+
+	if deadline, ok := r.contentDeadline(vgsc); ok && !time.Now().Before(deadline) {
+		if err := r.recordFailure(ctx, vgsc, originalStatus, volumegroupv1alpha1.ReasonTimeout,
+			fmt.Sprintf("VolumeSnapshots did not become ready to use by %s", deadline.Format(time.RFC3339))); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Timed out group snapshot won't be retried
 		return ctrl.Result{}, nil
 	}
 
-	pvcs, err := r.getSnapshotMissingVolumes(ctx, vgsc)
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionFalse,
+		volumegroupv1alpha1.ReasonNoFailure, "")
+
+	if cvgscName := vgsc.Labels[volumegroupv1alpha1.ClusterVolumeGroupSnapshotContentLabel]; cvgscName != "" && len(vgsc.Spec.SnapshotList) == 0 {
+		// SnapshotList is set once the VolumeSnapshots pre-bound to the VolumeSnapshotContents are created
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForContent, fmt.Sprintf("Waiting for ClusterVolumeGroupSnapshotContent %s to create the VolumeSnapshots", cvgscName))
+		if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Update of SnapshotList triggers the next reconciliation
+		return requeueUntil(r.contentDeadline(vgsc)), nil
+	}
+
+	snapshots, missing, err := r.memberVolumeSnapshots(ctx, vgsc)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if len(missing) > 0 {
+		// Members deleted out of band won't come back, so the group snapshot can't complete
+		if err := r.recordFailure(ctx, vgsc, originalStatus, volumegroupv1alpha1.ReasonSnapshotNotFound,
+			fmt.Sprintf("VolumeSnapshots %s in SnapshotList are not found", strings.Join(missing, ", "))); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Failure is recorded in the status, whose update triggers the post-snapshot hook
+		return ctrl.Result{}, nil
+	}
+
+	pvcs := getSnapshotMissingVolumes(vgsc, snapshots)
+
 	if len(pvcs) > 0 {
+		// Snapshots are taken only after the pre-snapshot hook succeeds
+		preHook, err := r.runPreSnapshotHooks(ctx, vgsc)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if preHook != nil && preHook.Phase == volumegroupv1alpha1.HookPhaseFailed {
+			if err := r.recordFailure(ctx, vgsc, originalStatus, volumegroupv1alpha1.ReasonHookFailed, preHook.Message); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Failure is recorded in the status, whose update triggers the post-snapshot hook
+			return ctrl.Result{}, nil
+		}
+
+		if preHook != nil && preHook.Phase == volumegroupv1alpha1.HookPhaseRunning {
+			setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+				volumegroupv1alpha1.ReasonHookRunning, fmt.Sprintf("Waiting for the %s hook to succeed", preHook.Name))
+			if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Progress of the Job is watched, while the HTTP endpoint is retried
+			return requeueForHook(requeueUntil(r.contentDeadline(vgsc)), vgsc.Spec.Hooks, preHook), nil
+		}
+
+		// Snapshots are taken only after the Pods of the scaled down workloads are gone
+		scaledDown, reason, message, err := r.scaleDownWorkloads(ctx, vgsc)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if reason != "" {
+			if err := r.recordFailure(ctx, vgsc, originalStatus, reason, message); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Failure is recorded in the status, whose update triggers the restoration of the workloads
+			return ctrl.Result{}, nil
+		}
+
+		if !scaledDown {
+			setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+				volumegroupv1alpha1.ReasonScalingDown, "Waiting for the workloads to be scaled down")
+			if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Pods aren't watched, so check them again later
+			return requeueNoLaterThan(requeueUntil(r.contentDeadline(vgsc)), time.Now().Add(workloadPollInterval)), nil
+		}
+
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonCreating, fmt.Sprintf("Creating VolumeSnapshots for %d PersistentVolumeClaims", len(pvcs)))
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonCreating, "VolumeSnapshots are being created")
+		if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		// Create VolumeSnapshot for pvcs
-		err := r.createVolumeSnapshots(ctx, vgsc, pvcs)
+		issuanceSkew, conflict, err := r.createVolumeSnapshots(ctx, vgsc, pvcs, snapshots)
+		if classErr, ok := asSnapshotClassError(err); ok {
+			// Report why the VolumeSnapshotClass can't be decided, which may be resolved before the deadline
+			setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionFalse,
+				classErr.reason, classErr.message)
+			setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+				classErr.reason, classErr.message)
+			if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, err
+		}
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{Requeue: true}, nil
+		if issuanceSkew != nil && len(pvcs) == len(vgsc.Spec.PersistentVolumeClaimList) {
+			// Skew is only meaningful when all the members are created at once
+			vgsc.Status.IssuanceSkew = issuanceSkew
+		}
+
+		if conflict != "" {
+			if err := r.recordFailure(ctx, vgsc, originalStatus, volumegroupv1alpha1.ReasonSnapshotConflict, conflict); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Conflicting VolumeSnapshot won't be adopted, so stop retrying
+			return ctrl.Result{}, nil
+		}
+
+		if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Progress of the created VolumeSnapshots is watched
+		return requeueUntil(r.contentDeadline(vgsc)), nil
 	}
 
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionSnapshotsCreated, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonCreated, fmt.Sprintf("%d VolumeSnapshots are created", len(vgsc.Spec.SnapshotList)))
+
 	// Update ReadyToUse
-	readyToUse, err := r.updateReadyToUse(ctx, vgsc)
+	readyToUse, err := r.updateReadyToUse(ctx, vgsc, snapshots)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	var postHook *volumegroupv1alpha1.HookStatus
+	if vgsc.Status.CreationTime != nil {
+		// All the snapshots are taken, so the applications are resumed without waiting for them to become ready to use
+		if err := r.restoreWorkloads(ctx, vgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		postHook, err = r.runPostSnapshotHooks(ctx, vgsc)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if meta.IsStatusConditionTrue(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed) {
+		// The failure is recorded before handling the partial set, which is retried on the failed one on error
+		if err := r.handlePartialSnapshots(ctx, vgsc); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Failed VolumeSnapshots won't become ready, so stop retrying.
+		// The status update triggers the post-snapshot hook.
+		return ctrl.Result{}, nil
+	}
+
 	if !readyToUse {
-		return ctrl.Result{Requeue: true}, nil
+		// Progress of the VolumeSnapshots is watched
+		return requeueForHook(requeueUntil(r.contentDeadline(vgsc)), vgsc.Spec.Hooks, postHook), nil
 	}
 
-	return ctrl.Result{}, nil
+	// Progress of the Job is watched, while the HTTP endpoint is retried
+	return requeueForHook(ctrl.Result{}, vgsc.Spec.Hooks, postHook), nil
 }
 
-func (r *VolumeGroupSnapshotContentReconciler) getSnapshotMissingVolumes(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) ([]string, error) {
-	requiredPvcs := make(map[string]bool, len(vgsc.Spec.PersistentVolumeClaimList))
+// memberVolumeSnapshots returns the VolumeSnapshots owned by or listed in the VolumeGroupSnapshotContent by name,
+// and the names in SnapshotList whose VolumeSnapshots are not found.
+// Owned VolumeSnapshots are looked up by one List from the cache, so only the pre-provisioned ones are got one by one.
+func (r *VolumeGroupSnapshotContentReconciler) memberVolumeSnapshots(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (map[string]*snapshotv1.VolumeSnapshot, []string, error) {
+	vsList := &snapshotv1.VolumeSnapshotList{}
+	if err := r.List(ctx, vsList, client.InNamespace(vgsc.Namespace), client.MatchingFields{volumeSnapshotOwnerIndex: vgsc.Name}); err != nil {
+		return nil, nil, err
+	}
 
-	// Set all pvcs in PersistentVolumeClaimList to requiredPvcs
-	for _, pvc := range vgsc.Spec.PersistentVolumeClaimList {
-		requiredPvcs[pvc] = true
+	snapshots := make(map[string]*snapshotv1.VolumeSnapshot, len(vsList.Items))
+	for i := range vsList.Items {
+		vs := &vsList.Items[i]
+		// Skip the ones left by a former VolumeGroupSnapshotContent with the same name
+		if metav1.IsControlledBy(vs, vgsc) {
+			snapshots[vs.Name] = vs
+		}
 	}
 
-	// Check if a snapshot for each PVC exists in SnapshotList
+	missing := []string{}
 	for _, vsName := range vgsc.Spec.SnapshotList {
-		vs := &snapshotv1.VolumeSnapshot{}
+		if _, ok := snapshots[vsName]; ok {
+			continue
+		}
 
-		err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}, vs)
+		vs := &snapshotv1.VolumeSnapshot{}
+		key := types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}
+		err := r.Get(ctx, key, vs)
+		if errors.IsNotFound(err) {
+			// The cache may not have seen the VolumeSnapshot created just now
+			err = r.apiReader().Get(ctx, key, vs)
+		}
 		if err != nil {
-			return []string{}, err
+			if errors.IsNotFound(err) {
+				missing = append(missing, vsName)
+				continue
+			}
+			return nil, nil, err
 		}
+		snapshots[vsName] = vs
+	}
 
-		if vs.Spec.Source.PersistentVolumeClaimName == nil {
-			continue
-		}
+	return snapshots, missing, nil
+}
+
+// apiReader returns the reader bypassing the cache
+func (r *VolumeGroupSnapshotContentReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
 
-		// Delete PersistentVolumeClaimName from requiredPvcs if exists
-		if _, ok := requiredPvcs[*vs.Spec.Source.PersistentVolumeClaimName]; ok {
-			delete(requiredPvcs, *vs.Spec.Source.PersistentVolumeClaimName)
+// contentDeadline returns the time by which the VolumeGroupSnapshotContent needs to become ready, and false if it has no deadline.
+// The deadline given by the VolumeGroupSnapshot takes precedence over the timeout from the creation of the content.
+func (r *VolumeGroupSnapshotContentReconciler) contentDeadline(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (time.Time, bool) {
+	if vgsc.Spec.Deadline != nil {
+		return vgsc.Spec.Deadline.Time, true
+	}
+	return deadlineFor(vgsc, vgsc.Spec.Timeout, r.DefaultTimeout)
+}
+
+// getSnapshotMissingVolumes returns the PersistentVolumeClaims in PersistentVolumeClaimList
+// whose VolumeSnapshots are not in SnapshotList
+func getSnapshotMissingVolumes(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, snapshots map[string]*snapshotv1.VolumeSnapshot) []string {
+	snapshotted := make(map[string]bool, len(vgsc.Spec.SnapshotList))
+	for _, vsName := range vgsc.Spec.SnapshotList {
+		if vs, ok := snapshots[vsName]; ok && vs.Spec.Source.PersistentVolumeClaimName != nil {
+			snapshotted[*vs.Spec.Source.PersistentVolumeClaimName] = true
 		}
 	}
 
-	// Covert map to array
 	volumes := []string{}
-	for pvc := range requiredPvcs {
-		volumes = append(volumes, pvc)
+	for _, pvc := range vgsc.Spec.PersistentVolumeClaimList {
+		if !snapshotted[pvc] {
+			volumes = append(volumes, pvc)
+		}
 	}
 
-	return volumes, nil
+	return volumes
 }
 
-func (r *VolumeGroupSnapshotContentReconciler) createVolumeSnapshots(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcs []string) error {
+// createVolumeSnapshots creates VolumeSnapshots for the PersistentVolumeClaims concurrently, and adds the created ones
+// to SnapshotList by a single patch. It returns the skew between the creations if all of them succeeded, and the reason
+// if a VolumeSnapshot with the same name exists but wasn't created for the group.
+func (r *VolumeGroupSnapshotContentReconciler) createVolumeSnapshots(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcs []string, snapshots map[string]*snapshotv1.VolumeSnapshot) (*metav1.Duration, string, error) {
+	// Adopt the VolumeSnapshots already created for the PersistentVolumeClaims but not added to SnapshotList
+	created := []string{}
+	remaining := make([]string, 0, len(pvcs))
 	for _, pvcName := range pvcs {
-		vs := r.volumeSnapshotFor(ctx, vgsc, pvcName)
+		vsName, err := r.ownedVolumeSnapshotFor(ctx, vgsc, pvcName)
+		if err != nil {
+			return nil, "", err
+		}
+		if vsName != "" {
+			created = append(created, vsName)
+			continue
+		}
+		remaining = append(remaining, pvcName)
+	}
+	adopted := len(created)
+
+	// Prepare all the VolumeSnapshots first so that they are created as close together as possible
+	prepared := make([]*snapshotv1.VolumeSnapshot, 0, len(remaining))
+	for _, pvcName := range remaining {
+		vs, err := r.volumeSnapshotFor(ctx, vgsc, pvcName)
+		if err != nil {
+			return nil, "", err
+		}
+		prepared = append(prepared, vs)
+	}
 
-		if err := r.Create(ctx, vs); err != nil {
-			if !errors.IsAlreadyExists(err) {
+	parallelism := r.MaxConcurrentSnapshotCreations
+	if parallelism <= 0 {
+		parallelism = defaultMaxConcurrentSnapshotCreations
+	}
+	if vgsc.Spec.IssuanceMode == volumegroupv1alpha1.IssuanceModeBurst {
+		// Create all of them at once
+		parallelism = len(prepared)
+	}
+
+	issued, conflicts, errs := createConcurrently(len(prepared), parallelism, func(i int) (string, error) {
+		return r.createVolumeSnapshot(ctx, vgsc, prepared[i], snapshots)
+	})
+
+	conflict := ""
+	var createErr error
+	for i, vs := range prepared {
+		switch {
+		case errs[i] != nil:
+			if createErr == nil {
+				createErr = errs[i]
+			}
+		case conflicts[i] != "":
+			if conflict == "" {
+				conflict = conflicts[i]
+			}
+		default:
+			created = append(created, vs.Name)
+		}
+	}
+
+	// Record the created VolumeSnapshots even if the others failed, so that they aren't created again
+	if err := r.addToSnapshotList(ctx, vgsc, created); err != nil {
+		return nil, "", err
+	}
+
+	if adopted > 0 || len(created) < len(prepared) || len(created) == 0 {
+		// Skew is unknown unless all of them are created at once
+		return nil, conflict, createErr
+	}
+
+	first, last := issued[0], issued[0]
+	for _, t := range issued[1:] {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	return &metav1.Duration{Duration: last.Sub(first)}, conflict, createErr
+}
+
+// ownedVolumeSnapshotFor returns the name of the VolumeSnapshot of the PersistentVolumeClaim created for the
+// VolumeGroupSnapshotContent, or an empty string if there is none
+func (r *VolumeGroupSnapshotContentReconciler) ownedVolumeSnapshotFor(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcName string) (string, error) {
+	snapshots, err := volumeSnapshotsForPVC(ctx, r.Client, vgsc.Namespace, pvcName)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range snapshots {
+		if metav1.IsControlledBy(&snapshots[i], vgsc) && snapshots[i].Labels[volumegroupv1alpha1.VolumeGroupSnapshotContentLabel] == vgsc.Name {
+			return snapshots[i].Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// createVolumeSnapshot creates the VolumeSnapshot unless it already exists.
+// It returns the reason if the existing VolumeSnapshot wasn't created for the group.
+func (r *VolumeGroupSnapshotContentReconciler) createVolumeSnapshot(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, vs *snapshotv1.VolumeSnapshot, snapshots map[string]*snapshotv1.VolumeSnapshot) (string, error) {
+	if existing, ok := snapshots[vs.Name]; ok {
+		// Already created, but not added to SnapshotList yet
+		return volumeSnapshotConflict(vgsc, "VolumeGroupSnapshotContent", existing, vs.Spec.Source), nil
+	}
+
+	if err := r.Create(ctx, vs); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return "", err
+		}
+
+		// Only adopt the VolumeSnapshot created for this group
+		existing := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vs.Name, Namespace: vs.Namespace}, existing); err != nil {
+			return "", err
+		}
+		return volumeSnapshotConflict(vgsc, "VolumeGroupSnapshotContent", existing, vs.Spec.Source), nil
+	}
+
+	return "", nil
+}
+
+// addToSnapshotList adds the names of the VolumeSnapshots to SnapshotList by a merge patch, retried on conflict
+func (r *VolumeGroupSnapshotContentReconciler) addToSnapshotList(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, vsNames []string) error {
+	if len(vsNames) == 0 {
+		return nil
+	}
+
+	latest := vgsc.DeepCopy()
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(vgsc), latest); err != nil {
 				return err
 			}
-			// Continue already exists case
 		}
+		first = false
 
-		// Add vs.Name to VolumeGroupSnapshotContent's SnapshotList
-		vgsc.Spec.SnapshotList = append(vgsc.Spec.SnapshotList, vs.Name)
-		// TODO: Consider also setting CreationTime somewhere
+		patch := client.MergeFromWithOptions(latest.DeepCopy(), client.MergeFromWithOptimisticLock{})
 
-		if err := r.Update(ctx, vgsc); err != nil {
+		listed := make(map[string]bool, len(latest.Spec.SnapshotList))
+		for _, vsName := range latest.Spec.SnapshotList {
+			listed[vsName] = true
+		}
+		for _, vsName := range vsNames {
+			if !listed[vsName] {
+				latest.Spec.SnapshotList = append(latest.Spec.SnapshotList, vsName)
+			}
+		}
+
+		if err := r.Patch(ctx, latest, patch); err != nil {
 			return err
 		}
 
+		latest.DeepCopyInto(vgsc)
+		return nil
+	})
+}
+
+// volumeSnapshotConflict returns the reason why the existing VolumeSnapshot can't be adopted by the owner of the kind
+// as the snapshot of the source, or an empty string if it was created for it
+func volumeSnapshotConflict(owner metav1.Object, ownerKind string, vs *snapshotv1.VolumeSnapshot, source snapshotv1.VolumeSnapshotSource) string {
+	if !metav1.IsControlledBy(vs, owner) {
+		return fmt.Sprintf("VolumeSnapshot %s/%s already exists and is not owned by %s %s", vs.Namespace, vs.Name, ownerKind, owner.GetName())
 	}
-	return nil
+
+	if !equality.Semantic.DeepEqual(vs.Spec.Source, source) {
+		return fmt.Sprintf("VolumeSnapshot %s already exists for a source other than %s", vs.Name, volumeSnapshotSourceName(source))
+	}
+
+	return ""
 }
 
-func (r *VolumeGroupSnapshotContentReconciler) volumeSnapshotFor(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcName string) *snapshotv1.VolumeSnapshot {
+// volumeSnapshotSourceName describes the source of a VolumeSnapshot for the messages
+func volumeSnapshotSourceName(source snapshotv1.VolumeSnapshotSource) string {
+	if source.PersistentVolumeClaimName != nil {
+		return fmt.Sprintf("PersistentVolumeClaim %s", *source.PersistentVolumeClaimName)
+	}
+	if source.VolumeSnapshotContentName != nil {
+		return fmt.Sprintf("VolumeSnapshotContent %s", *source.VolumeSnapshotContentName)
+	}
+	return "the empty source"
+}
+
+func (r *VolumeGroupSnapshotContentReconciler) volumeSnapshotFor(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcName string) (*snapshotv1.VolumeSnapshot, error) {
+	className, err := r.volumeSnapshotClassFor(ctx, vgsc, pvcName)
+	if err != nil {
+		return nil, err
+	}
+
 	vs := &snapshotv1.VolumeSnapshot{
 		ObjectMeta: metav1.ObjectMeta{
-			// TODO: Consider generating a better name for VolumeSnapshot from vgsc.Name and pvcName
-			Name:      fmt.Sprintf("vs-%s-%s", vgsc.Name, pvcName),
+			Name:      generatedName("vs", vgsc.Name, pvcName),
 			Namespace: vgsc.Namespace,
+			Labels: map[string]string{
+				volumegroupv1alpha1.VolumeGroupSnapshotContentLabel: vgsc.Name,
+			},
 		},
 		Spec: snapshotv1.VolumeSnapshotSpec{
 			Source: snapshotv1.VolumeSnapshotSource{
 				PersistentVolumeClaimName: &pvcName,
 			},
-			// TODO: VolumeSnapshotClassName should be passed so that non-default VolumeSnapshotClass can be used
-			// Should it be per PVC? If not, VolumeSnapshotClassName can be added to VolumeGroupSnapshot's spec and VolumeGroupSnapshotContent's spec directly
+			VolumeSnapshotClassName: className,
 		},
 	}
 
 	ctrl.SetControllerReference(vgsc, vs, r.Scheme)
 
-	return vs
+	return vs, nil
 }
 
-func (r *VolumeGroupSnapshotContentReconciler) updateReadyToUse(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (bool, error) {
+func (r *VolumeGroupSnapshotContentReconciler) volumeSnapshotClassFor(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, pvcName string) (*string, error) {
+	if vgsc.Spec.VolumeSnapshotClassName != nil {
+		return vgsc.Spec.VolumeSnapshotClassName, nil
+	}
+
+	// Choose the default VolumeSnapshotClass for the CSI driver of the PVC
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: vgsc.Namespace}, pvc); err != nil {
+		return nil, err
+	}
+
+	return defaultVolumeSnapshotClassFor(ctx, r.Client, pvc)
+}
+
+func (r *VolumeGroupSnapshotContentReconciler) updateReadyToUse(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, snapshots map[string]*snapshotv1.VolumeSnapshot) (bool, error) {
+	notReady := 0
+	failed := []*snapshotv1.VolumeSnapshot{}
+	members := []volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus{}
+
+	contents, err := boundVolumeSnapshotContents(ctx, r.Client, vgsc.Namespace)
+	if err != nil {
+		return false, err
+	}
+
 	for _, vsName := range vgsc.Spec.SnapshotList {
-		vs := &snapshotv1.VolumeSnapshot{}
+		vs, ok := snapshots[vsName]
+		if !ok {
+			return false, fmt.Errorf("VolumeSnapshot %s/%s in VolumeGroupSnapshotContent %s is not found", vgsc.Namespace, vsName, vgsc.Name)
+		}
 
-		if err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}, vs); err != nil {
-			return false, err
+		members = append(members, *memberStatusFor(vs, contents))
+
+		if vs.Status != nil && vs.Status.Error != nil {
+			// This VolumeSnapshot has failed
+			failed = append(failed, vs)
 		}
 
 		if vs.Status == nil || vs.Status.ReadyToUse == nil || !*vs.Status.ReadyToUse {
 			// This VolumeSnapshot isn't ready to use
-			return false, nil
+			notReady++
 		}
 	}
 
+	vgsc.Status.Members = members
+	setCreationTime(vgsc, members)
+	setCrashConsistent(vgsc)
+
+	if len(failed) > 0 {
+		vgsc.Status.Error = groupSnapshotErrorFor(failed)
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+			volumegroupv1alpha1.ReasonSnapshotFailed, *vgsc.Status.Error.Message)
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonSnapshotFailed, fmt.Sprintf("%d of %d VolumeSnapshots have failed", len(failed), len(vgsc.Spec.SnapshotList)))
+		return false, nil
+	}
+
+	if notReady > 0 {
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonWaitingForSnapshots, fmt.Sprintf("%d of %d VolumeSnapshots are not ready to use", notReady, len(vgsc.Spec.SnapshotList)))
+		return false, nil
+	}
+
 	// Update VolumeGroupSnapshotContent's ReadyToUse to true
 	ready := true
 	vgsc.Status.ReadyToUse = &ready
-
-	if err := r.Status().Update(ctx, vgsc); err != nil {
-		return false, err
-	}
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonReady, "All VolumeSnapshots are ready to use")
 
 	// All VolumeSnapshots in vgsc.Spec.SnapshotList are ready to use
 	return true, nil
 }
 
+// memberStatusFor returns the observed state of the VolumeSnapshot, with the snapshot handle and the precise creation time
+// taken from its bound VolumeSnapshotContent among the contents
+func memberStatusFor(vs *snapshotv1.VolumeSnapshot, contents map[string]*snapshotv1.VolumeSnapshotContent) *volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus {
+	member := &volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus{
+		VolumeSnapshotName:      vs.Name,
+		VolumeSnapshotClassName: vs.Spec.VolumeSnapshotClassName,
+	}
+
+	if vs.Spec.Source.PersistentVolumeClaimName != nil {
+		member.PersistentVolumeClaimName = *vs.Spec.Source.PersistentVolumeClaimName
+	}
+
+	if vs.Status == nil {
+		return member
+	}
+
+	member.VolumeSnapshotContentName = vs.Status.BoundVolumeSnapshotContentName
+	member.RestoreSize = vs.Status.RestoreSize
+	member.CreationTime = vs.Status.CreationTime
+	member.ReadyToUse = vs.Status.ReadyToUse
+	if vs.Status.Error != nil {
+		member.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{
+			Time:    vs.Status.Error.Time,
+			Message: vs.Status.Error.Message,
+		}
+	}
+
+	// Snapshot handle and the precise creation time are only available from the bound VolumeSnapshotContent
+	if vs.Status.BoundVolumeSnapshotContentName == nil {
+		return member
+	}
+
+	if vsc, ok := contents[*vs.Status.BoundVolumeSnapshotContentName]; ok && vsc.Status != nil {
+		member.SnapshotHandle = vsc.Status.SnapshotHandle
+		if vsc.Status.CreationTime != nil {
+			// CreationTime of the VolumeSnapshot is truncated to seconds, which is too coarse for the skew
+			member.CreationTime = &metav1.Time{Time: time.Unix(0, *vsc.Status.CreationTime)}
+		}
+	}
+
+	return member
+}
+
+// setCreationTime sets the creation time of the group from the CreationTime of the members,
+// once all the members have one
+func setCreationTime(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, members []volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus) {
+	var earliest, latest *metav1.Time
+	for _, member := range members {
+		if member.CreationTime == nil {
+			// Not all the snapshots are taken yet
+			return
+		}
+
+		if earliest == nil || member.CreationTime.Before(earliest) {
+			earliest = member.CreationTime
+		}
+		if latest == nil || latest.Before(member.CreationTime) {
+			latest = member.CreationTime
+		}
+	}
+
+	if earliest == nil {
+		return
+	}
+
+	vgsc.Status.CreationTime = earliest.DeepCopy()
+	vgsc.Status.LatestCreationTime = latest.DeepCopy()
+	vgsc.Status.CreationTimeSkew = &metav1.Duration{Duration: latest.Sub(earliest.Time)}
+}
+
+// setCrashConsistent sets CrashConsistent condition by comparing CreationTimeSkew with MaxSkew, if both are known
+func setCrashConsistent(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) {
+	if vgsc.Spec.MaxSkew == nil || vgsc.Status.CreationTimeSkew == nil {
+		return
+	}
+
+	skew, maxSkew := vgsc.Status.CreationTimeSkew.Duration, vgsc.Spec.MaxSkew.Duration
+	if skew > maxSkew {
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionCrashConsistent, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonMaxSkewExceeded, fmt.Sprintf("Creation time skew %s exceeds maxSkew %s", skew, maxSkew))
+		return
+	}
+
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionCrashConsistent, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonWithinMaxSkew, fmt.Sprintf("Creation time skew %s is within maxSkew %s", skew, maxSkew))
+}
+
+// groupSnapshotErrorFor summarizes the errors of the failed VolumeSnapshots into the earliest one
+func groupSnapshotErrorFor(failed []*snapshotv1.VolumeSnapshot) *volumegroupv1alpha1.VolumeGroupSnapshotError {
+	first := failed[0]
+	for _, vs := range failed[1:] {
+		if vs.Status.Error.Time != nil && (first.Status.Error.Time == nil || vs.Status.Error.Time.Before(first.Status.Error.Time)) {
+			first = vs
+		}
+	}
+
+	errorTime := metav1.Now()
+	if first.Status.Error.Time != nil {
+		errorTime = *first.Status.Error.Time
+	}
+
+	message := fmt.Sprintf("VolumeSnapshot %s has failed", first.Name)
+	if first.Status.Error.Message != nil {
+		message = fmt.Sprintf("%s: %s", message, *first.Status.Error.Message)
+	}
+	if len(failed) > 1 {
+		message = fmt.Sprintf("%s (and %d more VolumeSnapshots have failed)", message, len(failed)-1)
+	}
+
+	return &volumegroupv1alpha1.VolumeGroupSnapshotError{
+		Time:    &errorTime,
+		Message: &message,
+	}
+}
+
+// setContentFailed marks the VolumeGroupSnapshotContent as failed for the reason
+func setContentFailed(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, reason, message string) {
+	vgsc.Status.Error = &volumegroupv1alpha1.VolumeGroupSnapshotError{Time: &metav1.Time{Time: time.Now()}, Message: &message}
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionFailed, metav1.ConditionTrue,
+		reason, message)
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionReady, metav1.ConditionFalse,
+		reason, message)
+}
+
+// recordFailure records the failure of the VolumeGroupSnapshotContent, then handles the partial set.
+// The failure is persisted first, so that the VolumeSnapshots deleted by the rollback aren't looked up as members again
+// even if handling the partial set or the status update fails.
+func (r *VolumeGroupSnapshotContentReconciler) recordFailure(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, originalStatus *volumegroupv1alpha1.VolumeGroupSnapshotContentStatus, reason, message string) error {
+	setContentFailed(vgsc, reason, message)
+	if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+		return err
+	}
+
+	return r.handlePartialSnapshots(ctx, vgsc)
+}
+
+// handlePartialSnapshots applies the FailurePolicy to the failed VolumeGroupSnapshotContent,
+// unless the result is already recorded in the Partial condition
+func (r *VolumeGroupSnapshotContentReconciler) handlePartialSnapshots(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) error {
+	if meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionPartial) != nil {
+		return nil
+	}
+
+	originalStatus := vgsc.Status.DeepCopy()
+	if err := r.applyFailurePolicy(ctx, vgsc); err != nil {
+		return err
+	}
+
+	return r.updateStatus(ctx, vgsc, originalStatus)
+}
+
+// applyFailurePolicy deletes or labels the VolumeSnapshots created for the failed VolumeGroupSnapshotContent
+// according to its FailurePolicy
+func (r *VolumeGroupSnapshotContentReconciler) applyFailurePolicy(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) error {
+	snapshots := []*snapshotv1.VolumeSnapshot{}
+	for _, vsName := range vgsc.Spec.SnapshotList {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}, vs); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		if !metav1.IsControlledBy(vs, vgsc) {
+			// Only the VolumeSnapshots created for this VolumeGroupSnapshotContent are handled
+			continue
+		}
+		snapshots = append(snapshots, vs)
+	}
+
+	if vgsc.Spec.FailurePolicy == volumegroupv1alpha1.FailurePolicyRollback {
+		for _, vs := range snapshots {
+			if err := r.Delete(ctx, vs); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionPartial, metav1.ConditionFalse,
+			volumegroupv1alpha1.ReasonRolledBack, fmt.Sprintf("%d VolumeSnapshots are deleted", len(snapshots)))
+		return nil
+	}
+
+	// Retain the partial set, labeled so that it isn't mistaken for a complete group
+	for _, vs := range snapshots {
+		if vs.Labels[volumegroupv1alpha1.PartialLabel] == "true" {
+			continue
+		}
+
+		if vs.Labels == nil {
+			vs.Labels = map[string]string{}
+		}
+		vs.Labels[volumegroupv1alpha1.PartialLabel] = "true"
+
+		if err := r.Update(ctx, vs); err != nil {
+			return err
+		}
+	}
+
+	setCondition(&vgsc.Status.Conditions, vgsc.Generation, volumegroupv1alpha1.ConditionPartial, metav1.ConditionTrue,
+		volumegroupv1alpha1.ReasonRetained, fmt.Sprintf("%d of %d VolumeSnapshots are retained as a partial group",
+			len(snapshots), len(vgsc.Spec.PersistentVolumeClaimList)))
+	return nil
+}
+
+// finalize deletes or releases the VolumeSnapshots according to the DeletionPolicy and resumes the applications,
+// then removes the finalizer
+func (r *VolumeGroupSnapshotContentReconciler) finalize(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(vgsc, volumeGroupSnapshotContentFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	for _, vsName := range vgsc.Spec.SnapshotList {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := r.Get(ctx, types.NamespacedName{Name: vsName, Namespace: vgsc.Namespace}, vs); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, err
+		}
+
+		if vgsc.Spec.DeletionPolicy == volumegroupv1alpha1.DeletionPolicyDelete {
+			if err := r.Delete(ctx, vs); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+
+		// Remove the owner reference so that the VolumeSnapshot isn't garbage collected
+		ownerRefs := []metav1.OwnerReference{}
+		for _, ownerRef := range vs.OwnerReferences {
+			if ownerRef.UID != vgsc.UID {
+				ownerRefs = append(ownerRefs, ownerRef)
+			}
+		}
+		if len(ownerRefs) == len(vs.OwnerReferences) {
+			continue
+		}
+
+		vs.OwnerReferences = ownerRefs
+		if err := r.Update(ctx, vs); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Workloads aren't left scaled down by the deletion in the middle of the group snapshot
+	originalStatus := vgsc.Status.DeepCopy()
+	if err := r.restoreWorkloads(ctx, vgsc); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateStatus(ctx, vgsc, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.resumeBeforeDeletion(ctx, vgsc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !postSnapshotHooksCompleted(vgsc.Spec.Hooks, vgsc.Status.Hooks) {
+		// The finalizer is kept until the applications are resumed
+		return result, nil
+	}
+
+	controllerutil.RemoveFinalizer(vgsc, volumeGroupSnapshotContentFinalizer)
+	return ctrl.Result{}, r.Update(ctx, vgsc)
+}
+
+// updateStatus updates the status of the VolumeGroupSnapshotContent only if it differs from the original
+func (r *VolumeGroupSnapshotContentReconciler) updateStatus(ctx context.Context, vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent, original *volumegroupv1alpha1.VolumeGroupSnapshotContentStatus) error {
+	vgsc.Status.ObservedGeneration = vgsc.Generation
+
+	if equality.Semantic.DeepEqual(original, &vgsc.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, vgsc)
+}
+
+// volumeGroupSnapshotContentsForVolumeSnapshot maps a VolumeSnapshot to the VolumeGroupSnapshotContents listing it,
+// which includes pre-provisioned VolumeSnapshots not owned by them, and to the VolumeGroupSnapshotContent owning it,
+// which may not list it yet
+func (r *VolumeGroupSnapshotContentReconciler) volumeGroupSnapshotContentsForVolumeSnapshot(obj client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	ownerName := ""
+	if owner := metav1.GetControllerOf(obj); owner != nil && owner.APIVersion == volumegroupv1alpha1.GroupVersion.String() &&
+		owner.Kind == "VolumeGroupSnapshotContent" {
+		ownerName = owner.Name
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: ownerName, Namespace: obj.GetNamespace()},
+		})
+	}
+
+	vgscs, err := volumeGroupSnapshotContentsFor(context.Background(), r.Client, obj.GetNamespace(), obj.GetName())
+	if err != nil {
+		return requests
+	}
+
+	for _, vgsc := range vgscs {
+		if vgsc.Name == ownerName {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: vgsc.Name, Namespace: vgsc.Namespace},
+		})
+	}
+
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *VolumeGroupSnapshotContentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&volumegroupv1alpha1.VolumeGroupSnapshotContent{}).
+		Owns(&batchv1.Job{}).
+		// Owned VolumeSnapshots are mapped as well, so they are not watched by Owns
+		Watches(&source.Kind{Type: &snapshotv1.VolumeSnapshot{}},
+			handler.EnqueueRequestsFromMapFunc(r.volumeGroupSnapshotContentsForVolumeSnapshot)).
 		Complete(r)
 }
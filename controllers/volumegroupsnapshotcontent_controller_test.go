@@ -0,0 +1,622 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestContent returns a VolumeGroupSnapshotContent protected by the finalizer for the PersistentVolumeClaims
+func newTestContent(pvcs ...string) *volumegroupv1alpha1.VolumeGroupSnapshotContent {
+	return &volumegroupv1alpha1.VolumeGroupSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "vgsc",
+			Namespace:  "ns",
+			UID:        "vgsc-uid",
+			Generation: 1,
+			Finalizers: []string{volumeGroupSnapshotContentFinalizer},
+		},
+		Spec: volumegroupv1alpha1.VolumeGroupSnapshotContentSpec{
+			PersistentVolumeClaimList: pvcs,
+			SnapshotList:              []string{},
+		},
+	}
+}
+
+// newTestVolumeSnapshot returns the VolumeSnapshot of the PersistentVolumeClaim, controlled by the owner if given
+func newTestVolumeSnapshot(name, pvcName string, owner *volumegroupv1alpha1.VolumeGroupSnapshotContent) *snapshotv1.VolumeSnapshot {
+	vs := &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name + "-uid")},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source: snapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: &pvcName},
+		},
+	}
+	if owner != nil {
+		controller := true
+		vs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: volumegroupv1alpha1.GroupVersion.String(),
+			Kind:       "VolumeGroupSnapshotContent",
+			Name:       owner.Name,
+			UID:        owner.UID,
+			Controller: &controller,
+		}}
+	}
+	return vs
+}
+
+func TestRecordFailurePersistsFailureBeforeRollback(t *testing.T) {
+	vgsc := newTestContent("pvc-1")
+	vgsc.Spec.FailurePolicy = volumegroupv1alpha1.FailurePolicyRollback
+	vgsc.Spec.SnapshotList = []string{"vs-1"}
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+
+	c := newFakeClient(t, vgsc, vs)
+	getObject(t, c, vgsc)
+	ctx := context.Background()
+
+	// The members aren't deleted unless the failure is persisted
+	r := &VolumeGroupSnapshotContentReconciler{
+		Client: &failingStatusClient{Client: c, err: errors.NewConflict(volumegroupv1alpha1.GroupVersion.WithResource("volumegroupsnapshotcontents").GroupResource(), vgsc.Name, nil)},
+	}
+	if err := r.recordFailure(ctx, vgsc, vgsc.Status.DeepCopy(), volumegroupv1alpha1.ReasonTimeout, "timed out"); err == nil {
+		t.Fatal("expected the conflict to be returned")
+	}
+	getObject(t, c, vs)
+
+	r.Client = c
+	getObject(t, c, vgsc)
+	if err := r.recordFailure(ctx, vgsc, vgsc.Status.DeepCopy(), volumegroupv1alpha1.ReasonTimeout, "timed out"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vs), vs); !errors.IsNotFound(err) {
+		t.Fatalf("expected the member to be rolled back, got %v", err)
+	}
+
+	getObject(t, c, vgsc)
+	partial := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionPartial)
+	if !meta.IsStatusConditionTrue(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed) ||
+		partial == nil || partial.Reason != volumegroupv1alpha1.ReasonRolledBack {
+		t.Fatalf("expected the failure and the rollback to be persisted, got %+v", vgsc.Status.Conditions)
+	}
+}
+
+func TestReconcileHandlesPartialSnapshotsOfFailedContent(t *testing.T) {
+	// Failed is persisted, but the rollback didn't complete
+	vgsc := newTestContent("pvc-1")
+	vgsc.Spec.FailurePolicy = volumegroupv1alpha1.FailurePolicyRollback
+	vgsc.Spec.SnapshotList = []string{"vs-1"}
+	setContentFailed(vgsc, volumegroupv1alpha1.ReasonTimeout, "timed out")
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+
+	c := newFakeClient(t, vgsc, vs)
+	r := &VolumeGroupSnapshotContentReconciler{Client: c}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgsc)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(vs), vs); !errors.IsNotFound(err) {
+		t.Fatalf("expected the member to be rolled back, got %v", err)
+	}
+	getObject(t, c, vgsc)
+	if meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionPartial) == nil {
+		t.Fatal("expected the rollback to be recorded")
+	}
+}
+
+func TestReconcileFailsOnMissingListedSnapshot(t *testing.T) {
+	vgsc := newTestContent("pvc-1", "pvc-2")
+	vgsc.Spec.SnapshotList = []string{"vs-1", "vs-2"}
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+
+	c := newFakeClient(t, vgsc, vs)
+	r := &VolumeGroupSnapshotContentReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgsc)}
+	for i := 0; i < 2; i++ {
+		// The failure is recorded once, and isn't retried
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("expected the missing member to fail the group snapshot instead of an error, got %v", err)
+		}
+	}
+
+	getObject(t, c, vgsc)
+	failed := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed)
+	if failed == nil || failed.Status != metav1.ConditionTrue || failed.Reason != volumegroupv1alpha1.ReasonSnapshotNotFound {
+		t.Fatalf("expected the group snapshot to fail with %s, got %+v", volumegroupv1alpha1.ReasonSnapshotNotFound, failed)
+	}
+
+	// Retained members are labeled as a partial group
+	getObject(t, c, vs)
+	if vs.Labels[volumegroupv1alpha1.PartialLabel] != "true" {
+		t.Fatalf("expected the retained member to be labeled, got %v", vs.Labels)
+	}
+}
+
+func TestReconcileContentFailsAtDeadline(t *testing.T) {
+	// The content was created just now, but the deadline of its VolumeGroupSnapshot has passed
+	vgsc := newTestContent("pvc-1")
+	vgsc.CreationTimestamp = metav1.Now()
+	vgsc.Spec.Timeout = &metav1.Duration{Duration: time.Hour}
+	vgsc.Spec.Deadline = &metav1.Time{Time: time.Now().Add(-time.Second)}
+
+	c := newFakeClient(t, vgsc)
+	r := &VolumeGroupSnapshotContentReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgsc)}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	getObject(t, c, vgsc)
+	failed := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionFailed)
+	if failed == nil || failed.Status != metav1.ConditionTrue || failed.Reason != volumegroupv1alpha1.ReasonTimeout {
+		t.Fatalf("expected the content to time out, got %+v", failed)
+	}
+
+	// Before the deadline, the content is reconciled again when it passes
+	vgsc = newTestContent("pvc-1")
+	vgsc.CreationTimestamp = metav1.Now()
+	vgsc.Spec.Deadline = &metav1.Time{Time: time.Now().Add(time.Minute)}
+	if result := requeueUntil(r.contentDeadline(vgsc)); result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Fatalf("expected to be requeued by the deadline, got %+v", result)
+	}
+}
+
+// noContentGetClient fails the reads of single VolumeSnapshotContents, which are expected to be listed instead
+type noContentGetClient struct {
+	client.Client
+}
+
+func (c *noContentGetClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*snapshotv1.VolumeSnapshotContent); ok {
+		return fmt.Errorf("unexpected Get of VolumeSnapshotContent %s", key.Name)
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestUpdateReadyToUseListsBoundContents(t *testing.T) {
+	vgsc := newTestContent("pvc-1", "pvc-2")
+	vgsc.Spec.SnapshotList = []string{"vs-1", "vs-2"}
+
+	taken := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	objs := []client.Object{vgsc}
+	for i, name := range []string{"vs-1", "vs-2"} {
+		ready := true
+		contentName := "vsc-" + name
+		handle := "handle-" + name
+		creationTime := taken.Add(time.Duration(i) * 300 * time.Millisecond).UnixNano()
+
+		vs := newTestVolumeSnapshot(name, fmt.Sprintf("pvc-%d", i+1), vgsc)
+		vs.Status = &snapshotv1.VolumeSnapshotStatus{
+			BoundVolumeSnapshotContentName: &contentName,
+			CreationTime:                   &metav1.Time{Time: taken},
+			ReadyToUse:                     &ready,
+		}
+		vsc := &snapshotv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: contentName},
+			Spec:       snapshotv1.VolumeSnapshotContentSpec{VolumeSnapshotRef: corev1.ObjectReference{Namespace: "ns", Name: name}},
+			Status:     &snapshotv1.VolumeSnapshotContentStatus{SnapshotHandle: &handle, CreationTime: &creationTime},
+		}
+		objs = append(objs, vs, vsc)
+	}
+
+	// The content of the same name bound to another namespace isn't used
+	otherHandle := "other"
+	objs = append(objs, &snapshotv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "vsc-other"},
+		Spec:       snapshotv1.VolumeSnapshotContentSpec{VolumeSnapshotRef: corev1.ObjectReference{Namespace: "other", Name: "vs-1"}},
+		Status:     &snapshotv1.VolumeSnapshotContentStatus{SnapshotHandle: &otherHandle},
+	})
+
+	c := newFakeClient(t, objs...)
+	r := &VolumeGroupSnapshotContentReconciler{Client: &noContentGetClient{Client: c}}
+	ctx := context.Background()
+
+	snapshots, missing, err := r.memberVolumeSnapshots(ctx, vgsc)
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("expected all the members to be found, got %v %v", missing, err)
+	}
+	readyToUse, err := r.updateReadyToUse(ctx, vgsc, snapshots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !readyToUse {
+		t.Fatal("expected the group snapshot to be ready to use")
+	}
+
+	for _, member := range vgsc.Status.Members {
+		if member.SnapshotHandle == nil || *member.SnapshotHandle != "handle-"+member.VolumeSnapshotName {
+			t.Fatalf("expected the snapshot handle of the bound content, got %+v", member)
+		}
+	}
+	if vgsc.Status.CreationTimeSkew == nil || vgsc.Status.CreationTimeSkew.Duration != 300*time.Millisecond {
+		t.Fatalf("expected the skew between the precise creation times, got %v", vgsc.Status.CreationTimeSkew)
+	}
+}
+
+// newTestContentWithPVCs returns a VolumeGroupSnapshotContent with the VolumeSnapshotClass and its PersistentVolumeClaims
+func newTestContentWithPVCs(count int) (*volumegroupv1alpha1.VolumeGroupSnapshotContent, []client.Object) {
+	className := "class"
+	vgsc := newTestContent()
+	vgsc.Spec.VolumeSnapshotClassName = &className
+
+	objs := []client.Object{vgsc}
+	for i := 0; i < count; i++ {
+		pvcName := fmt.Sprintf("pvc-%d", i)
+		vgsc.Spec.PersistentVolumeClaimList = append(vgsc.Spec.PersistentVolumeClaimList, pvcName)
+		objs = append(objs, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "ns"}})
+	}
+
+	return vgsc, objs
+}
+
+// conflictingPatchClient lets another writer add the VolumeSnapshot to SnapshotList of the VolumeGroupSnapshotContent
+// right before the first patch, so that the patch conflicts
+type conflictingPatchClient struct {
+	client.Client
+	vsName  string
+	patches int
+}
+
+func (c *conflictingPatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if vgsc, ok := obj.(*volumegroupv1alpha1.VolumeGroupSnapshotContent); ok {
+		c.patches++
+		if c.patches == 1 {
+			latest := &volumegroupv1alpha1.VolumeGroupSnapshotContent{}
+			if err := c.Client.Get(ctx, client.ObjectKeyFromObject(vgsc), latest); err != nil {
+				return err
+			}
+			latest.Spec.SnapshotList = append(latest.Spec.SnapshotList, c.vsName)
+			if err := c.Client.Update(ctx, latest); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestCreateVolumeSnapshotsLimitsConcurrency(t *testing.T) {
+	vgsc, objs := newTestContentWithPVCs(5)
+	c := newFakeClient(t, objs...)
+	getObject(t, c, vgsc)
+	counter := &concurrencyClient{Client: c, delay: 20 * time.Millisecond}
+	r := &VolumeGroupSnapshotContentReconciler{Client: counter, Scheme: c.Scheme(), MaxConcurrentSnapshotCreations: 2}
+
+	if _, _, err := r.createVolumeSnapshots(context.Background(), vgsc, vgsc.Spec.PersistentVolumeClaimList, map[string]*snapshotv1.VolumeSnapshot{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if counter.created != 5 {
+		t.Fatalf("expected 5 VolumeSnapshots to be created, got %d", counter.created)
+	}
+	if counter.max != 2 {
+		t.Fatalf("expected 2 VolumeSnapshots to be created at the same time, got %d", counter.max)
+	}
+
+	getObject(t, c, vgsc)
+	if len(vgsc.Spec.SnapshotList) != 5 {
+		t.Fatalf("expected all the VolumeSnapshots to be listed, got %v", vgsc.Spec.SnapshotList)
+	}
+	for _, pvcName := range vgsc.Spec.PersistentVolumeClaimList {
+		vs := &snapshotv1.VolumeSnapshot{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: generatedName("vs", vgsc.Name, pvcName), Namespace: "ns"}, vs); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCreateVolumeSnapshotsAdoptsOwnedSnapshots(t *testing.T) {
+	vgsc, objs := newTestContentWithPVCs(2)
+
+	// The VolumeSnapshot was created for pvc-0, but wasn't added to SnapshotList
+	owned := newTestVolumeSnapshot("vs-owned", "pvc-0", vgsc)
+	owned.Labels = map[string]string{volumegroupv1alpha1.VolumeGroupSnapshotContentLabel: vgsc.Name}
+	// A VolumeSnapshot of pvc-1 created by someone else isn't adopted
+	foreign := newTestVolumeSnapshot("vs-foreign", "pvc-1", nil)
+
+	c := newFakeClient(t, append(objs, owned, foreign)...)
+	getObject(t, c, vgsc)
+	counter := &concurrencyClient{Client: c}
+	r := &VolumeGroupSnapshotContentReconciler{Client: counter, Scheme: c.Scheme()}
+
+	if _, _, err := r.createVolumeSnapshots(context.Background(), vgsc, vgsc.Spec.PersistentVolumeClaimList, map[string]*snapshotv1.VolumeSnapshot{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if counter.created != 1 {
+		t.Fatalf("expected only the VolumeSnapshot of pvc-1 to be created, got %d", counter.created)
+	}
+	getObject(t, c, vgsc)
+	want := []string{"vs-owned", generatedName("vs", vgsc.Name, "pvc-1")}
+	if !reflect.DeepEqual(vgsc.Spec.SnapshotList, want) {
+		t.Fatalf("expected %v to be listed, got %v", want, vgsc.Spec.SnapshotList)
+	}
+}
+
+func TestAddToSnapshotListRetriesOnConflict(t *testing.T) {
+	vgsc := newTestContent("pvc-1", "pvc-2")
+	c := newFakeClient(t, vgsc)
+	getObject(t, c, vgsc)
+	conflicting := &conflictingPatchClient{Client: c, vsName: "vs-1"}
+	r := &VolumeGroupSnapshotContentReconciler{Client: conflicting}
+
+	if err := r.addToSnapshotList(context.Background(), vgsc, []string{"vs-1", "vs-2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if conflicting.patches != 2 {
+		t.Fatalf("expected the conflicting patch to be retried once, got %d patches", conflicting.patches)
+	}
+	want := []string{"vs-1", "vs-2"}
+	if !reflect.DeepEqual(vgsc.Spec.SnapshotList, want) {
+		t.Fatalf("expected the content to be updated to %v, got %v", want, vgsc.Spec.SnapshotList)
+	}
+	getObject(t, c, vgsc)
+	if !reflect.DeepEqual(vgsc.Spec.SnapshotList, want) {
+		t.Fatalf("expected %v to be listed once, got %v", want, vgsc.Spec.SnapshotList)
+	}
+}
+
+func TestCreateVolumeSnapshotsInBurst(t *testing.T) {
+	vgsc, objs := newTestContentWithPVCs(5)
+	vgsc.Spec.IssuanceMode = volumegroupv1alpha1.IssuanceModeBurst
+	c := newFakeClient(t, objs...)
+	getObject(t, c, vgsc)
+	counter := &concurrencyClient{Client: c, delay: 20 * time.Millisecond}
+	r := &VolumeGroupSnapshotContentReconciler{Client: counter, Scheme: c.Scheme(), MaxConcurrentSnapshotCreations: 2}
+
+	issuanceSkew, _, err := r.createVolumeSnapshots(context.Background(), vgsc, vgsc.Spec.PersistentVolumeClaimList, map[string]*snapshotv1.VolumeSnapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit of the controller doesn't apply to the burst
+	if counter.max != 5 {
+		t.Fatalf("expected all the 5 VolumeSnapshots to be created at the same time, got %d", counter.max)
+	}
+	if issuanceSkew == nil || issuanceSkew.Duration < 0 {
+		t.Fatalf("expected the issuance skew to be measured, got %v", issuanceSkew)
+	}
+}
+
+func TestCreateVolumeSnapshotsIssuanceSkew(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing func(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) *snapshotv1.VolumeSnapshot
+		wantSkew bool
+	}{
+		{
+			name:     "all created",
+			wantSkew: true,
+		},
+		{
+			name: "some adopted",
+			existing: func(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) *snapshotv1.VolumeSnapshot {
+				vs := newTestVolumeSnapshot("vs-owned", "pvc-0", vgsc)
+				vs.Labels = map[string]string{volumegroupv1alpha1.VolumeGroupSnapshotContentLabel: vgsc.Name}
+				return vs
+			},
+		},
+		{
+			name: "some conflicting",
+			existing: func(vgsc *volumegroupv1alpha1.VolumeGroupSnapshotContent) *snapshotv1.VolumeSnapshot {
+				return newTestVolumeSnapshot(generatedName("vs", vgsc.Name, "pvc-0"), "other", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vgsc, objs := newTestContentWithPVCs(3)
+			if tt.existing != nil {
+				objs = append(objs, tt.existing(vgsc))
+			}
+			c := newFakeClient(t, objs...)
+			getObject(t, c, vgsc)
+			r := &VolumeGroupSnapshotContentReconciler{Client: c, Scheme: c.Scheme()}
+
+			issuanceSkew, _, err := r.createVolumeSnapshots(context.Background(), vgsc, vgsc.Spec.PersistentVolumeClaimList, map[string]*snapshotv1.VolumeSnapshot{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (issuanceSkew != nil) != tt.wantSkew {
+				t.Fatalf("expected the issuance skew to be known only if all the VolumeSnapshots are created at once, got %v", issuanceSkew)
+			}
+		})
+	}
+}
+
+func TestSetCrashConsistent(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSkew    *metav1.Duration
+		skew       *metav1.Duration
+		wantReason string
+	}{
+		{name: "within", maxSkew: &metav1.Duration{Duration: time.Second}, skew: &metav1.Duration{Duration: time.Second}, wantReason: volumegroupv1alpha1.ReasonWithinMaxSkew},
+		{name: "exceeded", maxSkew: &metav1.Duration{Duration: time.Second}, skew: &metav1.Duration{Duration: 2 * time.Second}, wantReason: volumegroupv1alpha1.ReasonMaxSkewExceeded},
+		{name: "no maxSkew", skew: &metav1.Duration{Duration: time.Hour}},
+		{name: "unknown skew", maxSkew: &metav1.Duration{Duration: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vgsc := newTestContent()
+			vgsc.Spec.MaxSkew = tt.maxSkew
+			vgsc.Status.CreationTimeSkew = tt.skew
+			setCrashConsistent(vgsc)
+
+			cond := meta.FindStatusCondition(vgsc.Status.Conditions, volumegroupv1alpha1.ConditionCrashConsistent)
+			if tt.wantReason == "" {
+				if cond != nil {
+					t.Fatalf("expected no condition, got %+v", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Reason != tt.wantReason {
+				t.Fatalf("expected the condition for %s, got %+v", tt.wantReason, cond)
+			}
+		})
+	}
+}
+
+func TestSetCreationTime(t *testing.T) {
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) *metav1.Time {
+		return &metav1.Time{Time: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name         string
+		creationTime []*metav1.Time
+		wantEarliest *metav1.Time
+		wantLatest   *metav1.Time
+		wantSkew     *metav1.Duration
+	}{
+		{
+			name:         "single member",
+			creationTime: []*metav1.Time{at(0)},
+			wantEarliest: at(0),
+			wantLatest:   at(0),
+			wantSkew:     &metav1.Duration{},
+		},
+		{
+			name:         "unordered members",
+			creationTime: []*metav1.Time{at(200 * time.Millisecond), at(0), at(1500 * time.Millisecond)},
+			wantEarliest: at(0),
+			wantLatest:   at(1500 * time.Millisecond),
+			wantSkew:     &metav1.Duration{Duration: 1500 * time.Millisecond},
+		},
+		{
+			name:         "member not taken yet",
+			creationTime: []*metav1.Time{at(0), nil},
+		},
+		{
+			name: "no members",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := []volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus{}
+			for i, creationTime := range tt.creationTime {
+				members = append(members, volumegroupv1alpha1.VolumeGroupSnapshotMemberStatus{
+					VolumeSnapshotName: fmt.Sprintf("vs-%d", i),
+					CreationTime:       creationTime,
+				})
+			}
+
+			vgsc := newTestContent()
+			setCreationTime(vgsc, members)
+
+			if !reflect.DeepEqual(vgsc.Status.CreationTime, tt.wantEarliest) {
+				t.Fatalf("expected the creation time %v, got %v", tt.wantEarliest, vgsc.Status.CreationTime)
+			}
+			if !reflect.DeepEqual(vgsc.Status.LatestCreationTime, tt.wantLatest) {
+				t.Fatalf("expected the latest creation time %v, got %v", tt.wantLatest, vgsc.Status.LatestCreationTime)
+			}
+			if !reflect.DeepEqual(vgsc.Status.CreationTimeSkew, tt.wantSkew) {
+				t.Fatalf("expected the creation time skew %v, got %v", tt.wantSkew, vgsc.Status.CreationTimeSkew)
+			}
+		})
+	}
+}
+
+func TestMemberStatusForTakesPreciseCreationTime(t *testing.T) {
+	truncated := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	precise := truncated.Add(750 * time.Millisecond)
+	contentName := "vsc-1"
+	creationTime := precise.UnixNano()
+
+	vs := newTestVolumeSnapshot("vs-1", "pvc-1", nil)
+	vs.Status = &snapshotv1.VolumeSnapshotStatus{
+		BoundVolumeSnapshotContentName: &contentName,
+		CreationTime:                   &metav1.Time{Time: truncated},
+	}
+
+	// Without the bound content, the creation time of the VolumeSnapshot is used
+	if member := memberStatusFor(vs, map[string]*snapshotv1.VolumeSnapshotContent{}); !member.CreationTime.Time.Equal(truncated) {
+		t.Fatalf("expected the creation time of the VolumeSnapshot, got %v", member.CreationTime)
+	}
+
+	contents := map[string]*snapshotv1.VolumeSnapshotContent{contentName: {
+		ObjectMeta: metav1.ObjectMeta{Name: contentName},
+		Status:     &snapshotv1.VolumeSnapshotContentStatus{CreationTime: &creationTime},
+	}}
+	if member := memberStatusFor(vs, contents); !member.CreationTime.Time.Equal(precise) {
+		t.Fatalf("expected the creation time %s of the content, got %v", precise, member.CreationTime)
+	}
+}
+
+func TestFinalizeContentEnforcesDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		policy      volumegroupv1alpha1.DeletionPolicy
+		wantDeleted bool
+	}{
+		{policy: volumegroupv1alpha1.DeletionPolicyDelete, wantDeleted: true},
+		{policy: volumegroupv1alpha1.DeletionPolicyRetain},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			vgsc := newTestContent("pvc-1")
+			vgsc.Spec.DeletionPolicy = tt.policy
+			vgsc.Spec.SnapshotList = []string{"vs-1", "vs-gone"}
+			now := metav1.Now()
+			vgsc.DeletionTimestamp = &now
+			vs := newTestVolumeSnapshot("vs-1", "pvc-1", vgsc)
+
+			c := newFakeClient(t, vgsc, vs)
+			r := &VolumeGroupSnapshotContentReconciler{Client: c}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(vgsc)}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.Get(context.Background(), client.ObjectKeyFromObject(vs), vs)
+			if tt.wantDeleted {
+				if !errors.IsNotFound(err) {
+					t.Fatalf("expected the VolumeSnapshot to be deleted, got %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected the VolumeSnapshot to be retained, got %v", err)
+				}
+				if len(vs.OwnerReferences) != 0 {
+					t.Fatalf("expected the retained VolumeSnapshot to be released from the content, got %v", vs.OwnerReferences)
+				}
+			}
+
+			// The content is gone once the finalizer is removed
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(vgsc), vgsc); !errors.IsNotFound(err) {
+				t.Fatalf("expected the finalizer to be removed, got %v %v", vgsc.Finalizers, err)
+			}
+		})
+	}
+}
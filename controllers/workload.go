@@ -0,0 +1,207 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// workloadPVCNames returns the names of the PersistentVolumeClaims used by the workload
+func workloadPVCNames(ctx context.Context, c client.Client, namespace string, ref *volumegroupv1alpha1.WorkloadReference) ([]string, error) {
+	key := types.NamespacedName{Name: ref.Name, Namespace: namespace}
+
+	switch ref.Kind {
+	case volumegroupv1alpha1.WorkloadKindPod:
+		pod := &corev1.Pod{}
+		if err := c.Get(ctx, key, pod); err != nil {
+			return nil, err
+		}
+		return podPVCNames(pod), nil
+
+	case volumegroupv1alpha1.WorkloadKindDeployment:
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, deploy); err != nil {
+			return nil, err
+		}
+		return controlledPodsPVCNames(ctx, c, deploy, deploy.Spec.Selector)
+
+	case volumegroupv1alpha1.WorkloadKindStatefulSet:
+		sts := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, key, sts); err != nil {
+			return nil, err
+		}
+
+		pvcNames, err := controlledPodsPVCNames(ctx, c, sts, sts.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+
+		// PVCs from volumeClaimTemplates are named <template>-<statefulset>-<ordinal>
+		replicas := int32(1)
+		if sts.Spec.Replicas != nil {
+			replicas = *sts.Spec.Replicas
+		}
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			for ordinal := int32(0); ordinal < replicas; ordinal++ {
+				pvcNames = append(pvcNames, fmt.Sprintf("%s-%s-%d", template.Name, sts.Name, ordinal))
+			}
+		}
+		return pvcNames, nil
+	}
+
+	return nil, fmt.Errorf("unsupported workload kind %s for %s/%s", ref.Kind, namespace, ref.Name)
+}
+
+// controlledPodsPVCNames returns the names of the PersistentVolumeClaims used by the pods matched by the selector
+// and controlled by the workload. Pods of other workloads sharing the labels are ignored.
+func controlledPodsPVCNames(ctx context.Context, c client.Client, obj client.Object, labelSelector *metav1.LabelSelector) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pods of a Deployment are controlled by its ReplicaSets
+	owners := map[types.UID]bool{}
+	if _, ok := obj.(*appsv1.Deployment); ok {
+		rsList := &appsv1.ReplicaSetList{}
+		if err := c.List(ctx, rsList, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		for i := range rsList.Items {
+			if metav1.IsControlledBy(&rsList.Items[i], obj) {
+				owners[rsList.Items[i].UID] = true
+			}
+		}
+	} else {
+		owners[obj.GetUID()] = true
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	pvcNames := []string{}
+	for i := range podList.Items {
+		if ref := metav1.GetControllerOf(&podList.Items[i]); ref != nil && owners[ref.UID] {
+			pvcNames = append(pvcNames, podPVCNames(&podList.Items[i])...)
+		}
+	}
+
+	return pvcNames, nil
+}
+
+// podWorkloadRefs returns the references to the workloads that the pod belongs to: the pod itself,
+// and the StatefulSet or the Deployment controlling it, through its ReplicaSet for the Deployment
+func podWorkloadRefs(ctx context.Context, c client.Client, pod client.Object) []volumegroupv1alpha1.WorkloadReference {
+	refs := []volumegroupv1alpha1.WorkloadReference{{Kind: volumegroupv1alpha1.WorkloadKindPod, Name: pod.GetName()}}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return refs
+	}
+
+	switch owner.Kind {
+	case volumegroupv1alpha1.WorkloadKindStatefulSet:
+		refs = append(refs, volumegroupv1alpha1.WorkloadReference{Kind: volumegroupv1alpha1.WorkloadKindStatefulSet, Name: owner.Name})
+
+	case "ReplicaSet":
+		rs := &appsv1.ReplicaSet{}
+		if err := c.Get(ctx, types.NamespacedName{Name: owner.Name, Namespace: pod.GetNamespace()}, rs); err != nil || rs.UID != owner.UID {
+			return refs
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == volumegroupv1alpha1.WorkloadKindDeployment {
+			refs = append(refs, volumegroupv1alpha1.WorkloadReference{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: rsOwner.Name})
+		}
+	}
+
+	return refs
+}
+
+// podPVCNames returns the names of the PersistentVolumeClaims used by the pod
+func podPVCNames(pod *corev1.Pod) []string {
+	return podSpecPVCNames(&pod.Spec)
+}
+
+// podSpecPVCNames returns the names of the PersistentVolumeClaims used by the pod spec
+func podSpecPVCNames(spec *corev1.PodSpec) []string {
+	pvcNames := []string{}
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			pvcNames = append(pvcNames, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+
+	return pvcNames
+}
+
+// workloadMountsPVC returns whether the Pods of the Deployment or StatefulSet mount any of the PersistentVolumeClaims.
+// The Pod template is checked instead of the Pods, which may be scaled down already.
+func workloadMountsPVC(obj client.Object, pvcNames []string) bool {
+	var template *corev1.PodTemplateSpec
+	var sts *appsv1.StatefulSet
+
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		template = &workload.Spec.Template
+	case *appsv1.StatefulSet:
+		template = &workload.Spec.Template
+		sts = workload
+	default:
+		return false
+	}
+
+	mounted := map[string]bool{}
+	for _, pvcName := range podSpecPVCNames(&template.Spec) {
+		mounted[pvcName] = true
+	}
+
+	for _, pvcName := range pvcNames {
+		if mounted[pvcName] || (sts != nil && isStatefulSetClaim(sts, pvcName)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStatefulSetClaim returns whether the PersistentVolumeClaim is created from a volumeClaimTemplate of the StatefulSet,
+// whose name is <template>-<statefulset>-<ordinal> for any ordinal
+func isStatefulSetClaim(sts *appsv1.StatefulSet, pvcName string) bool {
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		prefix := fmt.Sprintf("%s-%s-", template.Name, sts.Name)
+		if !strings.HasPrefix(pvcName, prefix) {
+			continue
+		}
+		if ordinal, err := strconv.Atoi(strings.TrimPrefix(pvcName, prefix)); err == nil && ordinal >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	volumegroupv1alpha1 "github.com/mkimuram/volumeGroupController/api/v1alpha1"
+)
+
+// newTestControlledPod returns a Pod with the shared labels mounting the PersistentVolumeClaim, controlled by the owner
+func newTestControlledPod(name, pvcName string, owner client.Object, ownerKind string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "ns", Labels: map[string]string{"app": "shared"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: owner.GetName(), UID: owner.GetUID(), Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}},
+		}}},
+	}
+}
+
+// newTestWorkloads returns a Deployment and a StatefulSet selecting the same labels,
+// with their Pods and the ReplicaSet of the Deployment
+func newTestWorkloads() []client.Object {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "shared"}}
+	controller := true
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "ns", UID: "deploy-uid"},
+		Spec:       appsv1.DeploymentSpec{Selector: selector},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "deploy-rs", Namespace: "ns", UID: "deploy-rs-uid", Labels: map[string]string{"app": "shared"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: deploy.Name, UID: deploy.UID, Controller: &controller}},
+	}}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts", Namespace: "ns", UID: "sts-uid"},
+		Spec:       appsv1.StatefulSetSpec{Selector: selector},
+	}
+
+	return []client.Object{
+		deploy, rs, sts,
+		newTestControlledPod("deploy-pod", "deploy-pvc", rs, "ReplicaSet"),
+		newTestControlledPod("sts-pod", "sts-pvc", sts, "StatefulSet"),
+	}
+}
+
+func TestWorkloadPVCNamesOfControlledPods(t *testing.T) {
+	c := newFakeClient(t, newTestWorkloads()...)
+
+	tests := []struct {
+		kind string
+		name string
+		want []string
+	}{
+		{kind: volumegroupv1alpha1.WorkloadKindDeployment, name: "deploy", want: []string{"deploy-pvc"}},
+		{kind: volumegroupv1alpha1.WorkloadKindStatefulSet, name: "sts", want: []string{"sts-pvc"}},
+		{kind: volumegroupv1alpha1.WorkloadKindPod, name: "sts-pod", want: []string{"sts-pvc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			got, err := workloadPVCNames(context.Background(), c, "ns", &volumegroupv1alpha1.WorkloadReference{Kind: tt.kind, Name: tt.name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVolumeGroupsForPod(t *testing.T) {
+	objs := newTestWorkloads()
+	for _, ref := range []volumegroupv1alpha1.WorkloadReference{
+		{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "deploy"},
+		{Kind: volumegroupv1alpha1.WorkloadKindStatefulSet, Name: "sts"},
+		{Kind: volumegroupv1alpha1.WorkloadKindPod, Name: "deploy-pod"},
+		{Kind: volumegroupv1alpha1.WorkloadKindDeployment, Name: "other"},
+	} {
+		ref := ref
+		objs = append(objs, &volumegroupv1alpha1.VolumeGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: ref.Kind + "-" + ref.Name, Namespace: "ns"},
+			Spec:       volumegroupv1alpha1.VolumeGroupSpec{WorkloadRef: &ref},
+		})
+	}
+	objs = append(objs, &volumegroupv1alpha1.VolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "selector", Namespace: "ns"}})
+
+	c := newFakeClient(t, objs...)
+	r := &VolumeGroupReconciler{Client: c}
+
+	tests := []struct {
+		pod  string
+		want []string
+	}{
+		{pod: "deploy-pod", want: []string{"Deployment-deploy", "Pod-deploy-pod"}},
+		{pod: "sts-pod", want: []string{"StatefulSet-sts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pod, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: tt.pod, Namespace: "ns"}, pod); err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, req := range r.volumeGroupsForPod(pod) {
+				got = append(got, req.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// A Pod whose ReplicaSet is gone maps only to the VolumeGroups referring to the Pod itself
+	orphan := newTestControlledPod("orphan", "pvc", &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "gone"}}, "ReplicaSet")
+	if got := r.volumeGroupsForPod(orphan); !reflect.DeepEqual(got, []reconcile.Request{}) {
+		t.Fatalf("expected no VolumeGroup, got %v", got)
+	}
+}
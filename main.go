@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -55,11 +57,18 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var groupSnapshotTimeout time.Duration
+	var maxConcurrentSnapshotCreations int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&groupSnapshotTimeout, "group-snapshot-timeout", 0,
+		"The default duration within which group snapshots need to become ready to use. "+
+			"Zero means group snapshots never time out.")
+	flag.IntVar(&maxConcurrentSnapshotCreations, "max-concurrent-snapshot-creations", 10,
+		"The maximum number of VolumeSnapshots created at the same time for a group snapshot.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -92,18 +101,50 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.VolumeGroupSnapshotReconciler{
+	if err = controllers.SetupIndexes(context.Background(), mgr); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.VolumeGroupReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeGroup")
+		os.Exit(1)
+	}
+	if err = (&controllers.VolumeGroupSnapshotReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		DefaultTimeout: groupSnapshotTimeout,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VolumeGroupSnapshot")
 		os.Exit(1)
 	}
 	if err = (&controllers.VolumeGroupSnapshotContentReconciler{
+		Client:                         mgr.GetClient(),
+		Scheme:                         mgr.GetScheme(),
+		DefaultTimeout:                 groupSnapshotTimeout,
+		MaxConcurrentSnapshotCreations: maxConcurrentSnapshotCreations,
+		APIReader:                      mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeGroupSnapshotContent")
+		os.Exit(1)
+	}
+	if err = (&controllers.ClusterVolumeGroupSnapshotReconciler{
+		Client:                         mgr.GetClient(),
+		Scheme:                         mgr.GetScheme(),
+		DefaultTimeout:                 groupSnapshotTimeout,
+		MaxConcurrentSnapshotCreations: maxConcurrentSnapshotCreations,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterVolumeGroupSnapshot")
+		os.Exit(1)
+	}
+	if err = (&controllers.ClusterVolumeGroupSnapshotContentReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "VolumeGroupSnapshotContent")
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterVolumeGroupSnapshotContent")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder